@@ -0,0 +1,235 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+type (
+	// Blocking represents a function that runs until ctx is cancelled.
+	Blocking func(ctx context.Context) error
+
+	// funcs is a Service built from a pair of start and stop functions.
+	funcs struct {
+		start Cleanup
+		stop  Cleanup
+	}
+
+	// background runs a blocking function in a goroutine and records its result.
+	background struct {
+		done     chan struct{}
+		err      error
+		name     string      // Hierarchical name of the service, set by the manager.
+		fail     func(error) // Reports a panic of the function while the service runs, set by the manager.
+		mu       sync.Mutex  // Guards stopping.
+		stopping bool        // Whether Stop has been called.
+	}
+
+	// parameterized is a Service built from a Parameterized start function and a Cleanup.
+	parameterized[T any] struct {
+		background
+		fn   Parameterized[T]
+		arg  T
+		stop Cleanup
+	}
+
+	// interruptable is a Service built from an Interruptable function.
+	interruptable struct {
+		background
+		fn      Interruptable
+		release chan any
+	}
+
+	// blocking is a Service built from a Blocking function.
+	blocking struct {
+		background
+		fn     Blocking
+		cancel context.CancelFunc
+	}
+)
+
+// FromFuncs creates a Service from a pair of start and stop functions. Either function may be nil.
+func FromFuncs(start, stop Cleanup) Service {
+	return &funcs{start: start, stop: stop}
+}
+
+// FromParameterized creates a Service that runs fn(arg) in a goroutine on Start and calls stop on Stop.
+//
+// fn is expected to block, e.g. echo.Echo.Start. An error returned by fn before Stop is called is reported by Stop;
+// whatever fn returns once stop has been called is ignored.
+func FromParameterized[T any](fn Parameterized[T], arg T, stop Cleanup) Service {
+	return &parameterized[T]{fn: fn, arg: arg, stop: stop}
+}
+
+// FromInterruptable creates a Service that runs fn in a goroutine on Start. Stop releases the interrupt channel and
+// waits for fn to return, reporting its error.
+func FromInterruptable(fn Interruptable) Service {
+	return &interruptable{fn: fn}
+}
+
+// FromBlocking creates a Service that runs fn in a goroutine on Start. Stop cancels the context passed to fn and waits
+// for it to return, reporting its error unless it is context.Canceled.
+//
+// The context passed to fn carries the values of the Start context but is not cancelled with it.
+func FromBlocking(fn Blocking) Service {
+	return &blocking{fn: fn}
+}
+
+func (s *funcs) Start(ctx context.Context) error {
+	if s.start == nil {
+		return nil
+	}
+
+	return s.start(ctx)
+}
+
+func (s *funcs) Stop(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+
+	return s.stop(ctx)
+}
+
+func (b *background) supervise(name string, _ *slog.Logger, fail func(error)) {
+	b.name, b.fail = name, fail
+}
+
+// run starts fn in a goroutine. A panic of fn is reported to the manager right away, unless the service is stopping,
+// and by Stop otherwise.
+func (b *background) run(fn func() error) {
+	b.done = make(chan struct{})
+	b.err = nil
+
+	b.mu.Lock()
+	b.stopping = false
+	b.mu.Unlock()
+
+	done, name, fail := b.done, b.name, b.fail
+
+	go func() {
+		// Without a manager, the name of the service is filled in when Stop returns the panic, see Graceful.wrap.
+		err := guard(name, "service panicked", fn)
+
+		b.mu.Lock()
+		_, panicked := Panicked(err)
+		report := panicked && fail != nil && !b.stopping
+		b.mu.Unlock()
+
+		if report {
+			fail(err)
+		} else {
+			b.err = err
+		}
+
+		close(done)
+	}()
+}
+
+// halt marks the service as stopping, so a panic of fn is returned by Stop rather than reported as a failure.
+func (b *background) halt() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopping = true
+}
+
+// exited returns the error of fn if it has already returned, and nil otherwise.
+func (b *background) exited() error {
+	if b.done == nil {
+		return nil
+	}
+
+	select {
+	case <-b.done:
+		return b.err
+	default:
+		return nil
+	}
+}
+
+// wait waits for fn to return, reporting false if ctx is done first.
+func (b *background) wait(ctx context.Context) bool {
+	if b.done == nil {
+		return true
+	}
+
+	select {
+	case <-b.done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *parameterized[T]) Start(ctx context.Context) error {
+	s.run(func() error { return s.fn(s.arg) })
+
+	return nil
+}
+
+func (s *parameterized[T]) Stop(ctx context.Context) error {
+	s.halt()
+
+	early := s.exited()
+
+	if s.stop == nil {
+		return early
+	}
+
+	return errors.Join(early, s.stop(ctx))
+}
+
+func (s *interruptable) Start(ctx context.Context) error {
+	release := make(chan any)
+	s.release = release
+	s.run(func() error { return s.fn(release) })
+
+	return nil
+}
+
+func (s *interruptable) Stop(ctx context.Context) error {
+	if s.release == nil {
+		return nil
+	}
+
+	s.halt()
+	close(s.release)
+	s.release = nil
+
+	if !s.wait(ctx) {
+		return ctx.Err()
+	}
+
+	return s.err
+}
+
+func (s *blocking) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.run(func() error { return s.fn(ctx) })
+
+	return nil
+}
+
+func (s *blocking) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.halt()
+	s.cancel()
+	s.cancel = nil
+
+	if !s.wait(ctx) {
+		return ctx.Err()
+	}
+
+	if errors.Is(s.err, context.Canceled) {
+		return nil
+	}
+
+	return s.err
+}
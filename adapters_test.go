@@ -0,0 +1,108 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/graceful"
+)
+
+func TestFromFuncs(t *testing.T) {
+	started, stopped := false, false
+
+	svc := graceful.FromFuncs(
+		func(ctx context.Context) error { started = true; return nil },
+		func(ctx context.Context) error { stopped = true; return nil },
+	)
+
+	g := graceful.New()
+	g.Add("funcs", svc)
+	g.Add("noop", graceful.FromFuncs(nil, nil))
+
+	ctx := context.Background()
+	assert.NoError(t, g.Start(ctx))
+	assert.True(t, started)
+	assert.NoError(t, g.Stop(ctx))
+	assert.True(t, stopped)
+}
+
+func TestFromParameterized(t *testing.T) {
+	t.Run("Stop calls cleanup", func(t *testing.T) {
+		fn := NewParameterized("string", "test", 0, 0)
+		svc := graceful.FromParameterized(fn.Start, "test", fn.Stop)
+
+		ctx := context.Background()
+		assert.NoError(t, svc.Start(ctx))
+		assert.NoError(t, svc.Stop(ctx))
+	})
+
+	t.Run("Stop reports early failure", func(t *testing.T) {
+		fn := NewParameterized("string", "test", 0, 0)
+		svc := graceful.FromParameterized(fn.Start, "wrong", fn.Stop)
+
+		ctx := context.Background()
+		assert.NoError(t, svc.Start(ctx))
+
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Error(t, svc.Stop(ctx))
+	})
+}
+
+func TestFromInterruptable(t *testing.T) {
+	t.Run("Stop releases and waits", func(t *testing.T) {
+		fn := NewInterruptable("interruptable", 100*time.Millisecond)
+		svc := graceful.FromInterruptable(fn.Run)
+
+		ctx := context.Background()
+		assert.NoError(t, svc.Start(ctx))
+
+		begin := time.Now()
+		assert.NoError(t, svc.Stop(ctx))
+		assert.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+	})
+
+	t.Run("Stop times out", func(t *testing.T) {
+		fn := NewInterruptable("interruptable", time.Second)
+		svc := graceful.FromInterruptable(fn.Run)
+
+		assert.NoError(t, svc.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, svc.Stop(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestFromBlocking(t *testing.T) {
+	t.Run("Stop cancels the run context", func(t *testing.T) {
+		svc := graceful.FromBlocking(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.NoError(t, svc.Start(ctx))
+
+		// Cancelling the start context must not stop the service.
+		cancel()
+
+		assert.NoError(t, svc.Stop(context.Background()))
+	})
+
+	t.Run("Stop reports run error", func(t *testing.T) {
+		svc := graceful.FromBlocking(func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("flush failed")
+		})
+
+		ctx := context.Background()
+		assert.NoError(t, svc.Start(ctx))
+		assert.EqualError(t, svc.Stop(ctx), "flush failed")
+	})
+}
@@ -0,0 +1,212 @@
+package graceful
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// check is a named condition reported by a probe endpoint.
+	check struct {
+		name string
+		err  error
+	}
+
+	// serviceJSON is the representation of a service in the /services endpoint.
+	serviceJSON struct {
+		Name     string     `json:"name"`
+		State    string     `json:"state"`
+		Deps     []string   `json:"deps"`
+		Uptime   float64    `json:"uptime_seconds"`
+		Restarts int        `json:"restarts"`
+		Error    string     `json:"error,omitempty"`
+		Health   string     `json:"health,omitempty"`
+		Probe    *probeJSON `json:"probe,omitempty"`
+	}
+
+	// probeJSON is the representation of the probes of a service in the verbose /services endpoint.
+	probeJSON struct {
+		Failures  int       `json:"failures"`
+		Successes int       `json:"successes"`
+		Error     string    `json:"error,omitempty"`
+		Time      time.Time `json:"time"`
+	}
+)
+
+// NewAdminHandler returns an http.Handler exposing the lifecycle of g for Kubernetes probes and debugging:
+//
+//   - /livez fails if a service has failed or is unhealthy.
+//   - /readyz fails until Start succeeds, as soon as shutdown begins, and while a service is not running or unhealthy.
+//   - /startupz fails until Start succeeds.
+//   - /services lists every service with its state, dependencies, uptime, restart count, last error and health as JSON.
+//
+// Probe endpoints answer 200 or 503 with a plain text body naming the failed checks. With ?verbose, every check is
+// listed, and /services includes the details of health probes. Mount the handler under a prefix with
+// http.StripPrefix.
+func NewAdminHandler(g *Graceful) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) { probe(w, r, "livez", livez(g)) })
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { probe(w, r, "readyz", readyz(g)) })
+	mux.HandleFunc("/startupz", func(w http.ResponseWriter, r *http.Request) { probe(w, r, "startupz", startupz(g)) })
+	mux.HandleFunc("/services", func(w http.ResponseWriter, r *http.Request) { services(w, r, g) })
+
+	return mux
+}
+
+// livez checks that no service has failed or is unhealthy.
+func livez(g *Graceful) []check {
+	checks := []check{{name: "ping"}}
+
+	for _, status := range g.Inspect() {
+		c := check{name: status.Name}
+
+		switch {
+		case status.State == StateFailed:
+			c.err = failure(status.Err, "service failed")
+		case status.Probe != nil && status.Probe.Health == HealthUnhealthy:
+			c.err = failure(status.Probe.Err, "service unhealthy")
+		}
+
+		checks = append(checks, c)
+	}
+
+	return checks
+}
+
+// readyz checks that the manager and all services are running and healthy.
+func readyz(g *Graceful) []check {
+	c := check{name: "lifecycle"}
+	if status := g.Status(); status != StateRunning && status != StateReloading {
+		c.err = fmt.Errorf("manager %s", status)
+	}
+
+	checks := []check{c}
+
+	for _, status := range g.Inspect() {
+		c := check{name: status.Name}
+
+		switch {
+		case status.State != StateRunning && status.State != StateReloading:
+			c.err = fmt.Errorf("service %s", status.State)
+		case status.Probe != nil && status.Probe.Health == HealthUnhealthy:
+			c.err = failure(status.Probe.Err, "service unhealthy")
+		}
+
+		checks = append(checks, c)
+	}
+
+	return checks
+}
+
+// startupz checks that Start succeeded.
+func startupz(g *Graceful) []check {
+	c := check{name: "start"}
+
+	switch status := g.Status(); status {
+	case StateRunning, StateReloading, StateStopping:
+	default:
+		c.err = fmt.Errorf("manager %s", status)
+	}
+
+	return []check{c}
+}
+
+// failure returns err, or an error with the given text if err is nil.
+func failure(err error, text string) error {
+	if err == nil {
+		return errors.New(text)
+	}
+
+	return err
+}
+
+// probe writes the outcome of checks in the plain text format of the Kubernetes API server health endpoints.
+func probe(w http.ResponseWriter, r *http.Request, endpoint string, checks []check) {
+	_, verbose := r.URL.Query()["verbose"]
+
+	var (
+		body   strings.Builder
+		failed bool
+	)
+
+	for _, c := range checks {
+		if c.err != nil {
+			failed = true
+
+			fmt.Fprintf(&body, "[-]%s failed: %v\n", c.name, c.err)
+		} else if verbose {
+			fmt.Fprintf(&body, "[+]%s ok\n", c.name)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	switch {
+	case failed:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(&body, "%s check failed\n", endpoint)
+	case verbose:
+		fmt.Fprintf(&body, "%s check passed\n", endpoint)
+	default:
+		body.WriteString("ok\n")
+	}
+
+	_, _ = w.Write([]byte(body.String()))
+}
+
+// services writes the services of g as JSON.
+func services(w http.ResponseWriter, r *http.Request, g *Graceful) {
+	_, verbose := r.URL.Query()["verbose"]
+
+	statuses := g.Inspect()
+	list := make([]serviceJSON, 0, len(statuses))
+
+	for _, status := range statuses {
+		svc := serviceJSON{
+			Name:     status.Name,
+			State:    status.State.String(),
+			Deps:     status.Deps,
+			Restarts: status.Restarts,
+		}
+
+		if (status.State == StateRunning || status.State == StateReloading) && !status.Since.IsZero() {
+			svc.Uptime = time.Since(status.Since).Seconds()
+		}
+
+		if status.Err != nil {
+			svc.Error = status.Err.Error()
+		}
+
+		if status.Probe != nil {
+			svc.Health = status.Probe.Health.String()
+
+			if verbose {
+				svc.Probe = &probeJSON{
+					Failures:  status.Probe.Failures,
+					Successes: status.Probe.Successes,
+					Time:      status.Probe.Time,
+				}
+
+				if status.Probe.Err != nil {
+					svc.Probe.Error = status.Probe.Err.Error()
+				}
+			}
+		}
+
+		list = append(list, svc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(struct {
+		Status   string        `json:"status"`
+		Health   string        `json:"health"`
+		Services []serviceJSON `json:"services"`
+	}{g.Status().String(), g.Health().String(), list})
+}
@@ -0,0 +1,162 @@
+package graceful_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// get requests path from h, returning the status code and body.
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	return rec.Code, string(body)
+}
+
+func TestAdminHandler(t *testing.T) {
+	t.Run("Probes follow the lifecycle", func(t *testing.T) {
+		release := make(chan struct{})
+		ready := make(chan struct{})
+
+		g := graceful.New()
+		g.Add("db", &MockSvc{name: "db"})
+		g.Add("api", graceful.FromFuncs(func(ctx context.Context) error {
+			close(ready)
+			<-release
+
+			return nil
+		}, nil), "db")
+
+		h := graceful.NewAdminHandler(g)
+
+		code, body := get(t, h, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "[-]lifecycle failed: manager stopped")
+
+		ctx := context.Background()
+		started := make(chan error, 1)
+
+		go func() { started <- g.Start(ctx) }()
+
+		<-ready
+
+		code, _ = get(t, h, "/startupz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+
+		code, body = get(t, h, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "[-]api failed: service starting")
+
+		code, _ = get(t, h, "/livez")
+		assert.Equal(t, http.StatusOK, code)
+
+		close(release)
+		require.NoError(t, <-started)
+
+		code, body = get(t, h, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok\n", body)
+
+		code, body = get(t, h, "/readyz?verbose")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "[+]lifecycle ok\n[+]api ok\n[+]db ok\nreadyz check passed\n", body)
+
+		code, _ = get(t, h, "/startupz")
+		assert.Equal(t, http.StatusOK, code)
+
+		require.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Readiness fails once shutdown begins", func(t *testing.T) {
+		var (
+			g       *graceful.Graceful
+			stopped int
+		)
+
+		g = graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.Service == "db" && ev.State == graceful.StateStopping {
+				stopped, _ = get(t, graceful.NewAdminHandler(g), "/readyz")
+			}
+		}))
+		g.Add("db", &MockSvc{name: "db"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		assert.Equal(t, http.StatusServiceUnavailable, stopped)
+	})
+
+	t.Run("Liveness fails with failed services", func(t *testing.T) {
+		g := graceful.New()
+		g.Add("db", graceful.FromFuncs(func(ctx context.Context) error { return errors.New("refused") }, nil))
+
+		h := graceful.NewAdminHandler(g)
+
+		require.Error(t, g.Start(context.Background()))
+
+		code, body := get(t, h, "/livez")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "[-]db failed: ")
+		assert.Contains(t, body, "refused")
+		assert.Contains(t, body, "livez check failed")
+	})
+
+	t.Run("Lists services as JSON", func(t *testing.T) {
+		child := graceful.New()
+		child.Add("db", &MockSvc{name: "db"})
+		child.Add("cache", &MockSvc{name: "cache"}, "db")
+
+		g := graceful.New()
+		g.Add("billing", child)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		time.Sleep(10 * time.Millisecond)
+
+		code, body := get(t, graceful.NewAdminHandler(g), "/services")
+		assert.Equal(t, http.StatusOK, code)
+
+		var report struct {
+			Status   string `json:"status"`
+			Health   string `json:"health"`
+			Services []struct {
+				Name     string   `json:"name"`
+				State    string   `json:"state"`
+				Deps     []string `json:"deps"`
+				Uptime   float64  `json:"uptime_seconds"`
+				Restarts int      `json:"restarts"`
+			} `json:"services"`
+		}
+
+		require.NoError(t, json.Unmarshal([]byte(body), &report))
+		assert.Equal(t, "running", report.Status)
+		assert.Equal(t, "healthy", report.Health)
+		require.Len(t, report.Services, 3)
+
+		assert.Equal(t, "billing", report.Services[0].Name)
+		assert.Equal(t, "billing/cache", report.Services[1].Name)
+		assert.Equal(t, []string{"billing/db"}, report.Services[1].Deps)
+		assert.Equal(t, "running", report.Services[1].State)
+		assert.Positive(t, report.Services[1].Uptime)
+		assert.Equal(t, "billing/db", report.Services[2].Name)
+
+		require.NoError(t, g.Stop(ctx))
+	})
+}
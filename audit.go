@@ -0,0 +1,217 @@
+package graceful
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// Audit appends a durable record of the lifecycle of a manager as JSON Lines, one object per line.
+	//
+	// Every record has a time and a kind:
+	//
+	//   - "register" when a service is added, with its dependencies.
+	//   - "transition" for every lifecycle event, with the state entered, the time spent in the previous state and the
+	//     error, if any. Readiness is the transition to "running".
+	//   - "restart" when a service is restarted, by Restart, a file watcher or a health probe.
+	//   - "signal" when the run loop receives a signal.
+	//   - "shutdown" for the steps of Stop: "drained" after the drain delay, "stuck" with the services still stopping
+	//     when its context is done, and "completed" once services stopped.
+	//
+	// Records are buffered and flushed when the manager settles, i.e. after Start, Stop and Reload, when a service
+	// fails, when a signal is received, and by Flush and Close.
+	Audit struct {
+		mu      sync.Mutex    // Guards the fields below.
+		w       *bufio.Writer // Buffers records written to the sink.
+		file    *os.File      // File the records are written to, if opened by OpenAudit.
+		path    string        // Path of the file.
+		max     int64         // Size a file may reach before it is rotated, 0 to never rotate.
+		backups int           // Number of rotated files kept.
+		size    int64         // Size of the current file.
+		err     error         // First write error.
+	}
+
+	// auditRecord is a line of the audit log.
+	auditRecord struct {
+		Time     time.Time `json:"time"`
+		Kind     string    `json:"kind"`
+		Service  string    `json:"service,omitempty"`
+		State    string    `json:"state,omitempty"`
+		Deps     []string  `json:"deps,omitempty"`
+		Signal   string    `json:"signal,omitempty"`
+		Step     string    `json:"step,omitempty"`
+		Duration float64   `json:"duration_seconds,omitempty"`
+		Error    string    `json:"error,omitempty"`
+	}
+)
+
+// NewAudit creates an Audit writing to w.
+func NewAudit(w io.Writer) *Audit {
+	return &Audit{w: bufio.NewWriter(w)}
+}
+
+// OpenAudit creates an Audit appending to the file at path. Once the file would grow beyond maxSize bytes, it is
+// renamed to path.1, previous backups are shifted to path.2 and so on up to backups files, and a new file is started.
+// A maxSize of zero disables rotation.
+func OpenAudit(path string, maxSize int64, backups int) (*Audit, error) {
+	a := &Audit{path: path, max: maxSize, backups: backups}
+
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// WithAudit makes the manager, and child managers without an audit of their own, record their lifecycle in a.
+func WithAudit(a *Audit) Option {
+	return func(g *Graceful) {
+		g.audit = a
+
+		g.observers = append(g.observers, func(ev Event) {
+			record := auditRecord{
+				Time:     ev.Time,
+				Kind:     "transition",
+				Service:  ev.Service,
+				State:    ev.State.String(),
+				Duration: ev.Duration.Seconds(),
+			}
+
+			if ev.Err != nil {
+				record.Error = ev.Err.Error()
+			}
+
+			a.write(record)
+
+			settled := ev.State == StateRunning || ev.State == StateStopped
+			if ev.State == StateFailed || ev.Service == "" && settled {
+				_ = a.Flush()
+			}
+		})
+	}
+}
+
+// Flush writes buffered records to the sink. It returns the first error encountered while writing records.
+func (a *Audit) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.w.Flush(); err != nil && a.err == nil {
+		a.err = err
+	}
+
+	return a.err
+}
+
+// Close flushes buffered records and closes the file opened by OpenAudit.
+func (a *Audit) Close() error {
+	err := a.Flush()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file != nil {
+		err = errors.Join(err, a.file.Close())
+		a.file = nil
+	}
+
+	return err
+}
+
+// write appends record, rotating the file first if needed.
+func (a *Audit) write(record auditRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file != nil && a.max > 0 && a.size > 0 && a.size+int64(len(line)) > a.max {
+		if err := a.rotate(); err != nil && a.err == nil {
+			a.err = err
+		}
+	}
+
+	n, err := a.w.Write(line)
+	a.size += int64(n)
+
+	if err != nil && a.err == nil {
+		a.err = err
+	}
+}
+
+// open opens the file at a.path for appending. The caller must hold a.mu, or own a exclusively.
+func (a *Audit) open() error {
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("graceful: audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("graceful: audit log: %w", err)
+	}
+
+	a.file, a.size = file, info.Size()
+	a.w = bufio.NewWriter(file)
+
+	return nil
+}
+
+// rotate moves the current file to the first backup and opens a new one. The caller must hold a.mu.
+func (a *Audit) rotate() error {
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+
+	if a.backups > 0 {
+		for i := a.backups - 1; i > 0; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", a.path, i), fmt.Sprintf("%s.%d", a.path, i+1))
+		}
+
+		if err := os.Rename(a.path, a.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(a.path); err != nil {
+		return err
+	}
+
+	return a.open()
+}
+
+// flush flushes the audit of g, or of its nearest parent that has one.
+func (g *Graceful) flush() {
+	for m := g; m != nil; m = m.parent {
+		if m.audit != nil {
+			_ = m.audit.Flush()
+			return
+		}
+	}
+}
+
+// journal appends record to the audit of g, or of its nearest parent that has one.
+func (g *Graceful) journal(record auditRecord) {
+	for m := g; m != nil; m = m.parent {
+		if m.audit != nil {
+			record.Time = time.Now()
+			m.audit.write(record)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package graceful_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+type auditRecord struct {
+	Kind    string   `json:"kind"`
+	Service string   `json:"service"`
+	State   string   `json:"state"`
+	Deps    []string `json:"deps"`
+	Step    string   `json:"step"`
+	Error   string   `json:"error"`
+}
+
+// audited parses JSON Lines audit records.
+func audited(t *testing.T, data []byte) []auditRecord {
+	t.Helper()
+
+	out := make([]auditRecord, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		var r auditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+
+		out = append(out, r)
+	}
+
+	return out
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("Records the lifecycle as JSON Lines", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		audit := graceful.NewAudit(&buf)
+
+		child := graceful.New()
+		child.Add("db", &MockSvc{name: "db"})
+
+		g := graceful.New(graceful.WithAudit(audit))
+		g.Add("billing", child)
+		g.Add("api", graceful.FromFuncs(nil, nil), "billing")
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		// Records are flushed once Start completes.
+		records := audited(t, buf.Bytes())
+		assert.Contains(t, records, auditRecord{Kind: "register", Service: "billing"})
+		assert.Contains(t, records, auditRecord{Kind: "register", Service: "billing/db"})
+		assert.Contains(t, records, auditRecord{Kind: "register", Service: "api", Deps: []string{"billing"}})
+		assert.Contains(t, records, auditRecord{Kind: "transition", Service: "billing/db", State: "running"})
+		assert.Equal(t, auditRecord{Kind: "transition", State: "running"}, records[len(records)-1])
+
+		require.NoError(t, g.Restart(ctx, "api"))
+		require.NoError(t, g.Stop(ctx))
+
+		records = audited(t, buf.Bytes())
+		assert.Contains(t, records, auditRecord{Kind: "restart", Service: "api"})
+		assert.Contains(t, records, auditRecord{Kind: "shutdown", Step: "drained"})
+		assert.Contains(t, records, auditRecord{Kind: "shutdown", Service: "billing", Step: "completed"})
+		assert.Contains(t, records, auditRecord{Kind: "shutdown", Step: "completed"})
+		assert.Equal(t, auditRecord{Kind: "transition", State: "stopped"}, records[len(records)-1])
+	})
+
+	t.Run("Rotates files by size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		audit, err := graceful.OpenAudit(path, 512, 2)
+		require.NoError(t, err)
+
+		g := graceful.New(graceful.WithAudit(audit))
+		g.Add("api", graceful.FromFuncs(nil, nil))
+
+		ctx := context.Background()
+		for range 10 {
+			require.NoError(t, g.Start(ctx))
+			require.NoError(t, g.Stop(ctx))
+		}
+
+		require.NoError(t, audit.Close())
+
+		for _, name := range []string{path, path + ".1", path + ".2"} {
+			info, err := os.Stat(name)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, info.Size(), int64(512))
+
+			data, err := os.ReadFile(name)
+			require.NoError(t, err)
+			assert.NotEmpty(t, audited(t, data))
+		}
+
+		assert.NoFileExists(t, path+".3")
+	})
+}
@@ -0,0 +1,117 @@
+package graceful
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// CriticalPath is the longest chain of dependencies through the services started by Start, weighted by the time
+	// each service took to start. Start cannot finish faster than the services on it take to start one after another,
+	// so speeding up boot means speeding up one of them.
+	//
+	// Child managers count as a single service; their own CriticalPath breaks them down.
+	CriticalPath struct {
+		Services []string                 // Services on the critical path, in start order.
+		Duration time.Duration            // Sum of the start durations along the path.
+		Starts   map[string]time.Duration // Time each started service took to start.
+		Slack    map[string]time.Duration // Time each started service could take longer without delaying Start.
+	}
+)
+
+// CriticalPath returns the critical path of the last successful Start. The second return value is false if Start has
+// not succeeded yet.
+func (g *Graceful) CriticalPath() (CriticalPath, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.path == nil {
+		return CriticalPath{}, false
+	}
+
+	return *g.path, true
+}
+
+// String describes the path and the start duration of each service on it, e.g.
+// "critical path 1.2s: db 500ms -> cache 300ms -> api 400ms".
+func (p CriticalPath) String() string {
+	steps := make([]string, 0, len(p.Services))
+	for _, name := range p.Services {
+		steps = append(steps, fmt.Sprintf("%s %s", name, p.Starts[name]))
+	}
+
+	return fmt.Sprintf("critical path %s: %s", p.Duration, strings.Join(steps, " -> "))
+}
+
+// critical computes the critical path through the started services from their measured start durations.
+func (g *Graceful) critical() CriticalPath {
+	g.mu.RLock()
+	// Services are appended to order once started, after their dependencies: it is a topological order.
+	order := slices.Clone(g.order)
+	starts := make(map[string]time.Duration, len(order))
+
+	for _, name := range order {
+		starts[name] = g.svcs[name].took
+	}
+	g.mu.RUnlock()
+
+	path := CriticalPath{Starts: starts, Slack: make(map[string]time.Duration, len(order))}
+
+	// Earliest finish of each service if every service started as soon as its dependencies were running.
+	finish := make(map[string]time.Duration, len(order))
+	via := make(map[string]string, len(order))
+	last := ""
+
+	for _, name := range order {
+		var begin time.Duration
+
+		for _, dep := range g.svcs[name].Deps {
+			if f, ok := finish[dep]; ok && (f > begin || via[name] == "") {
+				begin, via[name] = f, dep
+			}
+		}
+
+		finish[name] = begin + starts[name]
+
+		if last == "" || finish[name] > finish[last] {
+			last = name
+		}
+	}
+
+	if last == "" {
+		return path
+	}
+
+	path.Duration = finish[last]
+
+	// Latest finish of each service that does not delay the end of Start.
+	latest := make(map[string]time.Duration, len(order))
+
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+
+		if _, ok := latest[name]; !ok {
+			latest[name] = path.Duration
+		}
+
+		begin := latest[name] - starts[name]
+
+		for _, dep := range g.svcs[name].Deps {
+			if l, ok := latest[dep]; !ok || begin < l {
+				latest[dep] = begin
+			}
+		}
+
+		path.Slack[name] = latest[name] - finish[name]
+	}
+
+	for name := last; name != ""; name = via[name] {
+		path.Services = append(path.Services, name)
+	}
+
+	slices.Reverse(path.Services)
+
+	return path
+}
@@ -0,0 +1,54 @@
+package graceful_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// sleeper returns a service taking d to start.
+func sleeper(d time.Duration) graceful.Service {
+	return graceful.FromFuncs(func(ctx context.Context) error {
+		time.Sleep(d)
+		return nil
+	}, nil)
+}
+
+func TestGraceful_CriticalPath(t *testing.T) {
+	t.Run("Finds the longest chain and the slack of other services", func(t *testing.T) {
+		g := graceful.New()
+		g.Add("db", sleeper(50*time.Millisecond))
+		g.Add("cache", sleeper(10*time.Millisecond), "db")
+		g.Add("search", sleeper(60*time.Millisecond))
+		g.Add("api", sleeper(40*time.Millisecond), "db")
+		g.Add("web", &MockSvc{name: "web"}, "cache", "api")
+
+		_, ok := g.CriticalPath()
+		assert.False(t, ok)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		path, ok := g.CriticalPath()
+		require.True(t, ok)
+
+		assert.Equal(t, []string{"db", "api", "web"}, path.Services)
+		assert.GreaterOrEqual(t, path.Duration, 90*time.Millisecond)
+		assert.GreaterOrEqual(t, path.Starts["db"], 50*time.Millisecond)
+		assert.Contains(t, path.String(), "critical path ")
+		assert.Contains(t, path.String(), ": db ")
+
+		assert.Zero(t, path.Slack["db"])
+		assert.Zero(t, path.Slack["api"])
+		assert.Zero(t, path.Slack["web"])
+		assert.InDelta(t, 30*time.Millisecond, path.Slack["cache"], float64(15*time.Millisecond))
+		assert.InDelta(t, 30*time.Millisecond, path.Slack["search"], float64(15*time.Millisecond))
+
+		require.NoError(t, g.Stop(ctx))
+	})
+}
@@ -0,0 +1,330 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// CommandOption configures a Command service.
+	CommandOption func(*Command)
+
+	// Command is a Service supervising a subprocess.
+	//
+	// Start starts the process and, if configured, waits for a readiness check to pass. Stop sends SIGTERM and, if the
+	// process has not exited when the stop context is done, SIGKILL. If the process exits before Stop is called, the
+	// manager marks the service as failed with the exit status.
+	Command struct {
+		tpl      *exec.Cmd                       // Template the process is created from on every Start.
+		check    func(ctx context.Context) error // Readiness check, if any.
+		interval time.Duration                   // Interval between readiness checks.
+		signals  []os.Signal                     // Signals forwarded to the process.
+		logger   *slog.Logger                    // Logger output is forwarded to, if captured.
+		ring     *ring                           // Recent output, if captured.
+		mu       sync.Mutex                      // Guards the fields below.
+		name     string                          // Service name, set by the manager.
+		cmd      *exec.Cmd                       // Running process.
+		done     chan struct{}                   // Closed when the process has been reaped.
+		err      error                           // Result of Wait.
+		started  bool                            // Whether Start returned successfully.
+		stopping bool                            // Whether Stop has been called.
+		exited   bool                            // Whether the process has exited.
+		fail     func(error)                     // Reports a failure after Start, set by the manager.
+	}
+)
+
+// WithReadyCheck makes Start wait until check returns nil, calling it every interval until the process exits or the
+// start context is done.
+func WithReadyCheck(check func(ctx context.Context) error, interval time.Duration) CommandOption {
+	return func(c *Command) {
+		c.check = check
+		c.interval = interval
+	}
+}
+
+// WithSignals forwards the given signals received by the current process to the subprocess while it is running.
+func WithSignals(signals ...os.Signal) CommandOption {
+	return func(c *Command) {
+		c.signals = append(c.signals, signals...)
+	}
+}
+
+// WithOutput captures stdout and stderr of the process line by line, forwarding each line to logger with the service
+// name and stream as attributes, and keeping the last n lines. The kept lines are attached to the GracefulError when
+// the process fails. A nil logger uses slog.Default, and a negative n keeps no lines.
+//
+// Output is still written to Stdout and Stderr of the template command, if set.
+func WithOutput(logger *slog.Logger, n int) CommandOption {
+	return func(c *Command) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		c.logger = logger
+		c.ring = newRing(n)
+	}
+}
+
+// NewCommand creates a Service supervising the process described by cmd.
+//
+// cmd is used as a template and is never started itself, so the service can be started again after it stopped.
+func NewCommand(cmd *exec.Cmd, opts ...CommandOption) *Command {
+	c := &Command{tpl: cmd, interval: 100 * time.Millisecond, name: cmd.Path}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Pid returns the process id of the running process, or 0 if it is not running.
+func (c *Command) Pid() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cmd == nil || c.cmd.Process == nil || c.exited {
+		return 0
+	}
+
+	return c.cmd.Process.Pid
+}
+
+// Output returns the most recent lines of output, oldest first, prefixed by their stream. It returns nil unless the
+// output is captured with WithOutput.
+func (c *Command) Output() []string {
+	if c.ring == nil {
+		return nil
+	}
+
+	return c.ring.tail()
+}
+
+func (c *Command) supervise(name string, _ *slog.Logger, fail func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.name = name
+	c.fail = fail
+}
+
+// Start starts the process and waits for it to become ready.
+func (c *Command) Start(ctx context.Context) error {
+	cmd, flush := c.clone()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+
+	c.mu.Lock()
+	c.cmd, c.done, c.err = cmd, done, nil
+	c.started, c.stopping, c.exited = false, false, false
+	c.mu.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+
+		flush()
+
+		c.mu.Lock()
+		c.err, c.exited = err, true
+		report := c.started && !c.stopping
+		fail := c.fail
+		c.mu.Unlock()
+
+		close(done)
+
+		if report && fail != nil {
+			fail(c.exit(cmd, err))
+		}
+	}()
+
+	if len(c.signals) > 0 {
+		go c.forward(cmd, done)
+	}
+
+	if c.check != nil {
+		if err := c.ready(ctx, done); err != nil {
+			c.mu.Lock()
+			c.stopping = true
+			c.mu.Unlock()
+
+			_ = cmd.Process.Kill()
+			<-done
+
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.exited {
+		return c.exit(cmd, c.err)
+	}
+
+	c.started = true
+
+	return nil
+}
+
+// Stop sends SIGTERM to the process and waits for it to exit. If ctx is done first, the process is killed.
+func (c *Command) Stop(ctx context.Context) error {
+	c.mu.Lock()
+
+	if c.cmd == nil || c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.stopping = true
+	cmd, done, exited := c.cmd, c.done, c.exited
+	c.mu.Unlock()
+
+	// A process that exited on its own has already been reported as failed.
+	if exited {
+		return nil
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		<-done
+
+		return fmt.Errorf("graceful: process %s killed at stop deadline: %w", cmd.Path, ctx.Err())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Terminated by the signal we sent, rather than by another one such as SIGKILL from the OOM killer.
+	if cmd.ProcessState != nil {
+		if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() &&
+			status.Signal() == syscall.SIGTERM {
+			return nil
+		}
+	}
+
+	if c.err != nil {
+		return withOutput(fmt.Errorf("graceful: process %s stopped with %w", cmd.Path, c.err), c.Output())
+	}
+
+	return nil
+}
+
+// clone creates a new exec.Cmd from the template. If output is captured, the returned function flushes incomplete
+// lines and must be called once the process has been reaped.
+func (c *Command) clone() (*exec.Cmd, func()) {
+	cmd := &exec.Cmd{
+		Path:        c.tpl.Path,
+		Args:        c.tpl.Args,
+		Env:         c.tpl.Env,
+		Dir:         c.tpl.Dir,
+		Stdin:       c.tpl.Stdin,
+		Stdout:      c.tpl.Stdout,
+		Stderr:      c.tpl.Stderr,
+		ExtraFiles:  c.tpl.ExtraFiles,
+		SysProcAttr: c.tpl.SysProcAttr,
+		WaitDelay:   c.tpl.WaitDelay,
+		Err:         c.tpl.Err,
+	}
+
+	if c.ring == nil {
+		return cmd, func() {}
+	}
+
+	c.mu.Lock()
+	name := c.name
+	c.mu.Unlock()
+
+	stdout := &lines{stream: "stdout", service: name, logger: c.logger, ring: c.ring}
+	stderr := &lines{stream: "stderr", service: name, logger: c.logger, ring: c.ring}
+	cmd.Stdout = tee(c.tpl.Stdout, stdout)
+	cmd.Stderr = tee(c.tpl.Stderr, stderr)
+
+	// Children of the process may keep the pipes open after it exits.
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = time.Second
+	}
+
+	return cmd, func() {
+		stdout.flush()
+		stderr.flush()
+	}
+}
+
+// ready calls the readiness check until it passes, the process exits or ctx is done.
+func (c *Command) ready(ctx context.Context, done <-chan struct{}) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.check(ctx); err == nil {
+			return nil
+		}
+
+		select {
+		case <-done:
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			return c.exit(c.cmd, c.err)
+		case <-ctx.Done():
+			return fmt.Errorf("graceful: process %s not ready: %w", c.tpl.Path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// forward relays the configured signals to the process until it exits.
+func (c *Command) forward(cmd *exec.Cmd, done <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, c.signals...)
+
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case sig := <-sigs:
+			_ = cmd.Process.Signal(sig)
+		case <-done:
+			return
+		}
+	}
+}
+
+// exit describes an unexpected exit of cmd, attaching the captured output.
+func (c *Command) exit(cmd *exec.Cmd, err error) error {
+	return withOutput(exitError(cmd, err), c.Output())
+}
+
+// tee returns a writer writing to both w and l, or only to l if w is nil.
+func tee(w io.Writer, l *lines) io.Writer {
+	if w == nil {
+		return l
+	}
+
+	return io.MultiWriter(w, l)
+}
+
+// exitError describes an unexpected exit of cmd. err is the result of Wait; an *exec.ExitError carrying the exit
+// status is wrapped.
+func exitError(cmd *exec.Cmd, err error) error {
+	if err == nil {
+		return fmt.Errorf("graceful: process %s exited unexpectedly: exit status 0", cmd.Path)
+	}
+
+	return fmt.Errorf("graceful: process %s exited unexpectedly: %w", cmd.Path, err)
+}
@@ -0,0 +1,220 @@
+package graceful_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// shell returns a command running script with sh, skipping the test if sh is not available.
+func shell(t *testing.T, script string) *exec.Cmd {
+	t.Helper()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	return exec.Command("sh", "-c", script)
+}
+
+func TestCommand(t *testing.T) {
+	t.Run("Stop terminates the process", func(t *testing.T) {
+		cmd := graceful.NewCommand(shell(t, "sleep 10"))
+
+		g := graceful.New()
+		g.Add("sleep", cmd)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		assert.NotZero(t, cmd.Pid())
+
+		assert.NoError(t, g.Stop(ctx))
+		assert.Zero(t, cmd.Pid())
+
+		// The template can be started again.
+		require.NoError(t, g.Start(ctx))
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Stop kills the process at deadline", func(t *testing.T) {
+		cmd := graceful.NewCommand(shell(t, "trap '' TERM; sleep 10"))
+
+		require.NoError(t, cmd.Start(context.Background()))
+
+		// Give the shell time to install the trap.
+		time.Sleep(100 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		err := cmd.Stop(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Zero(t, cmd.Pid())
+	})
+
+	t.Run("Death by another signal while stopping is an error", func(t *testing.T) {
+		cmd := graceful.NewCommand(shell(t, "trap 'kill -KILL $$' TERM; while :; do sleep 0.05; done"))
+
+		require.NoError(t, cmd.Start(context.Background()))
+
+		// Give the shell time to install the trap.
+		time.Sleep(100 * time.Millisecond)
+
+		assert.ErrorContains(t, cmd.Stop(context.Background()), "signal: killed")
+	})
+
+	t.Run("Waits for readiness check", func(t *testing.T) {
+		ready := time.Now().Add(100 * time.Millisecond)
+		cmd := graceful.NewCommand(shell(t, "sleep 10"), graceful.WithReadyCheck(func(ctx context.Context) error {
+			if time.Now().Before(ready) {
+				return errors.New("not ready")
+			}
+
+			return nil
+		}, 10*time.Millisecond))
+
+		ctx := context.Background()
+		require.NoError(t, cmd.Start(ctx))
+		assert.False(t, time.Now().Before(ready))
+		assert.NoError(t, cmd.Stop(ctx))
+	})
+
+	t.Run("Exit before readiness fails Start", func(t *testing.T) {
+		cmd := graceful.NewCommand(shell(t, "exit 2"), graceful.WithReadyCheck(func(ctx context.Context) error {
+			return errors.New("not ready")
+		}, 10*time.Millisecond))
+
+		err := cmd.Start(context.Background())
+
+		var exit *exec.ExitError
+		require.ErrorAs(t, err, &exit)
+		assert.Equal(t, 2, exit.ExitCode())
+	})
+
+	t.Run("Unexpected exit marks the service failed", func(t *testing.T) {
+		failed := make(chan graceful.Event, 1)
+
+		g := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.State == graceful.StateFailed {
+				failed <- ev
+			}
+		}))
+		g.Add("proxy", graceful.NewCommand(shell(t, "sleep 0.1; exit 3")))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		select {
+		case ev := <-failed:
+			var exit *exec.ExitError
+			require.ErrorAs(t, ev.Err, &exit)
+			assert.Equal(t, 3, exit.ExitCode())
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for failure")
+		}
+
+		assert.NoError(t, g.Stop(ctx))
+	})
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestCommand_Output(t *testing.T) {
+	t.Run("Failure carries output tail", func(t *testing.T) {
+		logs := &syncBuffer{}
+		logger := slog.New(slog.NewTextHandler(logs, nil))
+
+		failed := make(chan graceful.Event, 1)
+
+		g := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.State == graceful.StateFailed {
+				failed <- ev
+			}
+		}))
+
+		script := "for i in 1 2 3 4; do echo line$i; done; sleep 0.1; echo oops >&2; exit 1"
+		g.Add("migrate", graceful.NewCommand(shell(t, script), graceful.WithOutput(logger, 3)))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		select {
+		case ev := <-failed:
+			var gerr *graceful.GracefulError
+			require.ErrorAs(t, ev.Err, &gerr)
+			assert.Equal(t, []string{"stdout: line3", "stdout: line4", "stderr: oops"}, gerr.Output)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for failure")
+		}
+
+		assert.Contains(t, logs.String(), "msg=line1 service=migrate stream=stdout")
+		assert.Contains(t, logs.String(), "msg=oops service=migrate stream=stderr")
+
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Start failure carries output", func(t *testing.T) {
+		cmd := graceful.NewCommand(shell(t, "printf 'no config'; exit 1"),
+			graceful.WithOutput(slog.New(slog.NewTextHandler(&syncBuffer{}, nil)), 10),
+			graceful.WithReadyCheck(func(ctx context.Context) error { return errors.New("not ready") }, 10*time.Millisecond),
+		)
+
+		g := graceful.New()
+		g.Add("proxy", cmd)
+
+		err := g.Start(context.Background())
+
+		var gerr *graceful.GracefulError
+		require.ErrorAs(t, err, &gerr)
+		assert.Equal(t, []string{"stdout: no config"}, gerr.Output)
+		assert.Equal(t, []string{"stdout: no config"}, cmd.Output())
+	})
+
+	t.Run("Negative output size keeps no lines", func(t *testing.T) {
+		logs := &syncBuffer{}
+		cmd := graceful.NewCommand(shell(t, "echo hello"), graceful.WithOutput(slog.New(slog.NewTextHandler(logs, nil)), -1))
+
+		g := graceful.New()
+		g.Add("hello", cmd)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		require.Eventually(t, func() bool {
+			return strings.Contains(logs.String(), "msg=hello")
+		}, 2*time.Second, 10*time.Millisecond)
+
+		assert.Empty(t, cmd.Output())
+
+		_ = g.Stop(ctx)
+	})
+}
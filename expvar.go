@@ -0,0 +1,97 @@
+package graceful
+
+import (
+	"expvar"
+	"time"
+)
+
+type (
+	// varJSON is the representation of a manager published by Var.
+	varJSON struct {
+		Status   string                `json:"status"`
+		Services map[string]varService `json:"services"`
+		Order    []string              `json:"order"`
+		Shutdown *varShutdown          `json:"shutdown"`
+	}
+
+	// varService is the representation of a service published by Var.
+	varService struct {
+		State string   `json:"state"`
+		Deps  []string `json:"deps"`
+		Error string   `json:"error,omitempty"`
+	}
+
+	// varShutdown is the representation of the last Stop published by Var.
+	varShutdown struct {
+		Began    string  `json:"began"`
+		Drain    float64 `json:"drain_seconds"`
+		Duration float64 `json:"duration_seconds"`
+		Error    string  `json:"error,omitempty"`
+	}
+)
+
+// WithExpvar publishes Var under name, so the lifecycle of the manager shows up at /debug/vars. Like expvar.Publish,
+// it panics if name is already in use.
+func WithExpvar(name string) Option {
+	return func(g *Graceful) {
+		expvar.Publish(name, g.Var())
+	}
+}
+
+// Var returns an expvar.Var rendering the live state of g as JSON: the status of the manager, the state, dependencies
+// and last error of every service, the order in which services started, and the report of the last Stop, if any.
+func (g *Graceful) Var() expvar.Var {
+	return expvar.Func(func() any {
+		v := varJSON{
+			Status:   g.Status().String(),
+			Services: make(map[string]varService),
+			Order:    g.started(),
+		}
+
+		for _, status := range g.Inspect() {
+			svc := varService{State: status.State.String(), Deps: status.Deps}
+			if status.Err != nil {
+				svc.Error = status.Err.Error()
+			}
+
+			v.Services[status.Name] = svc
+		}
+
+		if report, ok := g.LastShutdown(); ok {
+			v.Shutdown = &varShutdown{
+				Began:    report.Began.Format(time.RFC3339Nano),
+				Drain:    report.Drain.Seconds(),
+				Duration: report.Duration.Seconds(),
+			}
+
+			if report.Err != nil {
+				v.Shutdown.Error = report.Err.Error()
+			}
+		}
+
+		return v
+	})
+}
+
+// started returns the hierarchical names of the started services in the order they started, the services of a child
+// manager following the manager itself.
+func (g *Graceful) started() []string {
+	g.mu.RLock()
+	order := make([]string, len(g.order))
+	copy(order, g.order)
+	g.mu.RUnlock()
+
+	names := make([]string, 0, len(order))
+
+	for _, name := range order {
+		names = append(names, name)
+
+		if child, ok := g.svcs[name].Service.(*Graceful); ok {
+			for _, sub := range child.started() {
+				names = append(names, name+"/"+sub)
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,69 @@
+package graceful_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestGraceful_Var(t *testing.T) {
+	t.Run("Publishes live state", func(t *testing.T) {
+		child := graceful.New()
+		child.Add("db", &MockSvc{name: "db"})
+
+		// Names can only be published once per process.
+		name := fmt.Sprintf("graceful_test_%d", time.Now().UnixNano())
+
+		g := graceful.New(graceful.WithExpvar(name))
+		g.Add("billing", child)
+		g.Add("api", &MockSvc{name: "api"}, "billing")
+		g.Add("cache", graceful.FromFuncs(nil, func(ctx context.Context) error { return errors.New("flush failed") }))
+
+		type vars struct {
+			Status   string `json:"status"`
+			Services map[string]struct {
+				State string   `json:"state"`
+				Deps  []string `json:"deps"`
+				Error string   `json:"error"`
+			} `json:"services"`
+			Order    []string `json:"order"`
+			Shutdown *struct {
+				Error string `json:"error"`
+			} `json:"shutdown"`
+		}
+
+		published := expvar.Get(name)
+		require.NotNil(t, published)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		var v vars
+		require.NoError(t, json.Unmarshal([]byte(published.String()), &v))
+
+		assert.Equal(t, "running", v.Status)
+		assert.Equal(t, "running", v.Services["billing/db"].State)
+		assert.Equal(t, []string{"billing"}, v.Services["api"].Deps)
+		assert.ElementsMatch(t, []string{"billing", "billing/db", "api", "cache"}, v.Order)
+		assert.Greater(t, slices.Index(v.Order, "api"), slices.Index(v.Order, "billing/db"))
+		assert.Nil(t, v.Shutdown)
+
+		require.Error(t, g.Stop(ctx))
+
+		require.NoError(t, json.Unmarshal([]byte(published.String()), &v))
+		assert.Equal(t, "failed", v.Status)
+		assert.Contains(t, v.Services["cache"].Error, "flush failed")
+		require.NotNil(t, v.Shutdown)
+		assert.Contains(t, v.Shutdown.Error, "flush failed")
+	})
+}
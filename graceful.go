@@ -79,8 +79,14 @@ package graceful
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 type (
@@ -94,10 +100,15 @@ type (
 
 	// ServiceDef defines a service with its dependencies.
 	ServiceDef struct {
-		Service Service   // service implementation
-		Name    string    // service name
-		Deps    []string  // list of dependencies
-		once    sync.Once // Ensures Start is called only once for each service.
+		Service  Service       // service implementation
+		Name     string        // service name
+		Deps     []string      // list of dependencies
+		state    State         // Current lifecycle state.
+		err      error         // Last error reported by the service.
+		since    time.Time     // Time the service entered the running state.
+		restarts int           // Number of times the service was restarted.
+		changed  time.Time     // Time of the last transition.
+		took     time.Duration // Time the last successful start took.
 	}
 
 	// Services is a map of service names to their definitions.
@@ -105,26 +116,62 @@ type (
 
 	// Graceful manages the lifecycle of a set of services with dependencies.
 	// It ensures that services are started in the correct order and stopped in the reverse order.
+	//
+	// Graceful itself implements Service, so a manager can be added to a parent manager. The child's services are then
+	// reported under hierarchical names such as "billing/db".
 	Graceful struct {
-		svcs  Services   // Map of services.
-		graph sync.Map   // Dependency graph of services.
-		order []string   // Ordered list of service names.
-		cherr chan error // Channel for errors encountered during service lifecycle.
+		svcs        Services        // Map of services.
+		graph       sync.Map        // Dependency graph of services.
+		order       []string        // Ordered list of service names.
+		mu          sync.RWMutex    // Guards order, status and the lifecycle fields of each ServiceDef.
+		status      State           // Lifecycle state of the manager itself.
+		changed     time.Time       // Time of the last transition of the manager itself.
+		name        string          // Name under which the manager is registered in its parent.
+		parent      *Graceful       // Parent manager, if any.
+		observers   []Observer      // Lifecycle event observers.
+		validators  []func() error  // Configuration checks run by Validate.
+		triggers    []trigger       // Signal handlers of the run loop.
+		failures    chan error      // Receives failures of services after they started.
+		prober      *prober         // Health probes, nil unless enabled by WithHealthChecks.
+		drainDelay  time.Duration   // Time Stop waits before stopping services.
+		report      *ShutdownReport // Report of the last Stop, guarded by mu.
+		tracer      Tracer          // Traces lifecycle operations, nil to use the tracer of the parent.
+		path        *CriticalPath   // Critical path of the last successful Start, guarded by mu.
+		logger      *slog.Logger    // Logs lifecycle transitions, nil to use the logger of the parent.
+		level       slog.Leveler    // Level of lifecycle records, nil to use the level of the parent.
+		audit       *Audit          // Records the lifecycle, nil to use the audit of the parent.
+		termination string          // Path Run writes its termination message to, empty to disable.
+		dump        string          // File goroutine stacks of a stuck Stop are written to, empty to use the logger.
+		repanic     bool            // Whether Run panics again with a recovered panic once it has shut down.
 	}
 
+	// Option configures a Graceful manager.
+	Option func(*Graceful)
+
 	// GracefulError is an error that occurred during service lifecycle.
 	GracefulError struct {
-		Service string // Service name that failed
-		Reason  string // Reason for the error
-		Err     error  // Underlying error
+		Service string   // Service name that failed
+		Reason  string   // Reason for the error
+		Err     error    // Underlying error
+		Output  []string // Most recent output of the service, if captured
+		Panic   any      // Value the service panicked with, if the error was recovered from a panic
+		Stack   []byte   // Stack of the goroutine that panicked, nil unless the error was recovered from a panic
 	}
 )
 
+// ErrAlreadyStarted is returned by Start if the manager is neither stopped nor failed, or still runs services.
+var ErrAlreadyStarted = errors.New("graceful: manager already started")
+
 // Error returns a formatted error string.
 func (e *GracefulError) Error() string {
 	return fmt.Sprintf("Error in service %s: %s: %v", e.Service, e.Reason, e.Err)
 }
 
+// Unwrap returns the underlying error.
+func (e *GracefulError) Unwrap() error {
+	return e.Err
+}
+
 // NewGracefulError creates a new GracefulError.
 func NewGracefulError(service, reason string, err error) *GracefulError {
 	return &GracefulError{Service: service, Reason: reason, Err: err}
@@ -174,12 +221,12 @@ func (g *Graceful) sort() ([]string, error) {
 		// Update in-degree of neighbors (remove outgoing edge)
 		deps, ok := g.graph.Load(name)
 		if !ok {
-			return nil, NewGracefulError(name, "dependency graph missing entry", nil)
+			return nil, NewGracefulError(g.qualify(name), "dependency graph missing entry", nil)
 		}
 
 		list, ok := deps.([]string)
 		if !ok {
-			return nil, NewGracefulError(name, "invalid dependency type", nil)
+			return nil, NewGracefulError(g.qualify(name), "invalid dependency type", nil)
 		}
 
 		for _, dep := range list {
@@ -210,16 +257,101 @@ func (g *Graceful) sort() ([]string, error) {
 }
 
 // Add adds a new service to the graceful manager.
+//
+// If svc is itself a *Graceful, it becomes a child of g and its services are named relative to name.
 func (g *Graceful) Add(name string, svc Service, deps ...string) {
+	if child, ok := svc.(*Graceful); ok && child != g {
+		child.parent = g
+		child.name = name
+	}
+
 	g.svcs[name] = &ServiceDef{Service: svc, Name: name, Deps: deps}
 	g.graph.Store(name, deps)
+
+	g.register(g.svcs[name])
+}
+
+// register records the registration of svc, and of the services of svc if it is a child manager, in the audit log.
+func (g *Graceful) register(svc *ServiceDef) {
+	deps := make([]string, len(svc.Deps))
+	for i, dep := range svc.Deps {
+		deps[i] = g.qualify(dep)
+	}
+
+	g.journal(auditRecord{Kind: "register", Service: g.qualify(svc.Name), Deps: deps})
+
+	if child, ok := svc.Service.(*Graceful); ok {
+		for _, name := range slices.Sorted(maps.Keys(child.svcs)) {
+			child.register(child.svcs[name])
+		}
+	}
 }
 
 // Start starts all registered services in the order defined by their dependencies.
 // It starts services concurrently and waits for all services to start successfully.
+//
+// A service is started only after all of its dependencies have started. If a service fails to start, services that
+// depend on it are not started, and the errors are returned once every other service has settled. Services that did
+// start keep running until Stop is called. Start returns ErrAlreadyStarted unless the manager is stopped or failed, and
+// while services started by a previous Start that failed still run; Stop stops them.
 func (g *Graceful) Start(ctx context.Context) error {
-	g.cherr = make(chan error)
-	started := make(map[string]bool)
+	g.mu.Lock()
+	if g.status != StateStopped && g.status != StateFailed || len(g.order) > 0 {
+		g.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+
+	// Claim the manager before observers are notified, so concurrent calls cannot start it twice.
+	g.status = StateStarting
+	g.mu.Unlock()
+
+	ctx, span := g.trace(ctx, "graceful.start")
+
+	g.advance(StateStarting, nil)
+
+	if err := g.start(ctx); err != nil {
+		g.advance(StateFailed, err)
+		span.End(err)
+
+		return err
+	}
+
+	path := g.critical()
+
+	g.mu.Lock()
+	g.path = &path
+	g.mu.Unlock()
+
+	span.End(nil)
+
+	g.advance(StateRunning, nil)
+
+	return nil
+}
+
+// Validate checks the dependency graph and the configuration of the manager without starting any service. It is
+// called by Start.
+func (g *Graceful) Validate() error {
+	if _, err := g.sort(); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	for _, validate := range g.validators {
+		if err := validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// start starts the services, see Start.
+func (g *Graceful) start(ctx context.Context) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
 
 	sorted, err := g.sort()
 	if err != nil {
@@ -229,32 +361,101 @@ func (g *Graceful) Start(ctx context.Context) error {
 	for _, name := range sorted {
 		svc, ok := g.svcs[name]
 		if !ok {
-			return NewGracefulError(name, "service not found", nil)
+			return NewGracefulError(g.qualify(name), "service not found", nil)
 		}
 
-		if svc == nil {
-			return NewGracefulError(name, "service is nil", nil)
+		if svc == nil || svc.Service == nil {
+			return NewGracefulError(g.qualify(name), "service is nil", nil)
 		}
+	}
+
+	g.mu.Lock()
+	g.order = g.order[:0]
+	g.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	done := make(map[string]chan struct{}, len(sorted))
+	for _, name := range sorted {
+		done[name] = make(chan struct{})
+	}
+
+	for _, name := range sorted {
+		svc := g.svcs[name]
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer close(done[name])
 
-		svc.once.Do(func() {
 			for _, dep := range svc.Deps {
-				for {
-					_, ok := started[dep]
-					if ok {
-						break
-					}
+				<-done[dep]
+
+				if g.state(dep) != StateRunning {
+					return
 				}
 			}
 
-			go func() {
-				if err := svc.Service.Start(ctx); err != nil {
-					g.cherr <- NewGracefulError(name, "service start failed", err)
-				}
-			}()
+			if err := g.startService(ctx, svc); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// startService starts a single service and records it as started.
+func (g *Graceful) startService(ctx context.Context, svc *ServiceDef) error {
+	ctx, span := g.traceService(ctx, "graceful.service.start", svc)
+
+	g.transition(svc, StateStarting, nil)
+
+	if sup, ok := svc.Service.(supervised); ok {
+		logger, _ := g.log()
+		sup.supervise(g.qualify(svc.Name), logger, func(err error) { g.fail(svc, err) })
+	}
+
+	start := func() error { return svc.Service.Start(ctx) }
 
-			g.order = append(g.order, name)
-			started[name] = true
-		})
+	if err := guard(g.qualify(svc.Name), "service start panicked", start); err != nil {
+		err = g.wrap(svc, "service start failed", err)
+
+		// A child manager that failed to start may have started some of its services, so it is stopped with g.
+		if child, ok := svc.Service.(*Graceful); ok && len(child.started()) > 0 {
+			g.mu.Lock()
+			if !slices.Contains(g.order, svc.Name) {
+				g.order = append(g.order, svc.Name)
+			}
+			g.mu.Unlock()
+		}
+
+		g.transition(svc, StateFailed, err)
+		span.End(err)
+
+		return err
+	}
+
+	span.End(nil)
+
+	g.mu.Lock()
+	if !slices.Contains(g.order, svc.Name) {
+		g.order = append(g.order, svc.Name)
+	}
+	g.mu.Unlock()
+
+	// The service may have failed already, e.g. a Runner returning right after Start.
+	if g.state(svc.Name) == StateStarting {
+		g.transition(svc, StateRunning, nil)
 	}
 
 	return nil
@@ -262,39 +463,216 @@ func (g *Graceful) Start(ctx context.Context) error {
 
 // Stop stops all registered services in the reverse order they were started.
 // It stops services concurrently and waits for all services to stop gracefully.
+//
+// A service is stopped only after every started service depending on it has stopped. With WithDrainDelay, Stop first
+// waits for the drain delay; LastShutdown reports how long it waited. Once ctx is done, Stop no longer waits for
+// services still stopping and returns an error naming them, see WithGoroutineDump.
 func (g *Graceful) Stop(ctx context.Context) error {
-	var wg sync.WaitGroup
+	report := &ShutdownReport{Began: time.Now()}
+
+	ctx, span := g.trace(ctx, "graceful.stop")
+
+	g.advance(StateStopping, nil)
+
+	report.Drain = g.drain(ctx)
+
+	manager := ""
+	if g.parent != nil {
+		manager = g.parent.qualify(g.name)
+	}
+
+	g.journal(auditRecord{Kind: "shutdown", Service: manager, Step: "drained", Duration: report.Drain.Seconds()})
+
+	err := g.stop(ctx, manager)
+
+	report.Duration = time.Since(report.Began)
+	report.Err = err
+
+	completed := auditRecord{Kind: "shutdown", Service: manager, Step: "completed", Duration: report.Duration.Seconds()}
+	if err != nil {
+		completed.Error = err.Error()
+	}
+
+	g.journal(completed)
+
+	g.mu.Lock()
+	g.report = report
+	g.mu.Unlock()
+
+	span.End(err)
+
+	if err != nil {
+		g.advance(StateFailed, err)
+		return err
+	}
+
+	g.advance(StateStopped, nil)
+
+	return nil
+}
+
+// stop stops the services, see Stop. It returns once ctx is done, with an error naming the services still stopping
+// unless an enclosing Stop reports them.
+func (g *Graceful) stop(ctx context.Context, manager string) error {
+	// Stuck services are reported by the outermost Stop only.
+	outermost := ctx.Value(watchedKey{}) == nil
+	if outermost {
+		ctx = context.WithValue(ctx, watchedKey{}, true)
+	}
+
+	g.mu.RLock()
+	order := make([]string, len(g.order))
+	copy(order, g.order)
+	g.mu.RUnlock()
+
+	// dependents maps each started service to the started services that depend on it.
+	dependents := make(map[string][]string, len(order))
+	done := make(map[string]chan struct{}, len(order))
+
+	for _, name := range order {
+		done[name] = make(chan struct{})
+	}
+
+	for _, name := range order {
+		for _, dep := range g.svcs[name].Deps {
+			if _, ok := done[dep]; ok {
+				dependents[dep] = append(dependents[dep], name)
+			}
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
 	// Use the reverse of the started order to stop services
-	for i := len(g.order) - 1; i >= 0; i-- {
-		name := g.order[i]
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		svc := g.svcs[name]
 
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
+			defer close(done[name])
 
-			for _, cmp := range g.svcs {
-				if cmp.Name == name {
-					if err := cmp.Service.Stop(ctx); err != nil {
-						g.cherr <- NewGracefulError(name, "service stop failed", err)
-					}
+			for _, dependent := range dependents[name] {
+				<-done[dependent]
+			}
 
-					return
-				}
+			if err := g.stopService(ctx, svc); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
 			}
 		}()
 	}
-	wg.Wait()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
 
 	select {
-	case err := <-g.cherr:
+	case <-stopped:
+	case <-ctx.Done():
+	}
+
+	// Child managers return as the deadline passes too, so their stuck services are found and reported by the
+	// outermost Stop, once.
+	if ctx.Err() != nil && outermost {
+		if stopping, waiting := g.stuck(done); len(stopping) > 0 || len(waiting) > 0 {
+			g.reportStuck(stopping, waiting, manager)
+
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("graceful: shutdown deadline reached, %s", describeStuck(stopping, waiting)))
+			mu.Unlock()
+		}
+	}
+
+	g.mu.Lock()
+	g.order = g.order[:0]
+	g.mu.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// stopService stops a single service.
+func (g *Graceful) stopService(ctx context.Context, svc *ServiceDef) error {
+	ctx, span := g.traceService(ctx, "graceful.service.stop", svc)
+
+	g.transition(svc, StateStopping, nil)
+
+	stop := func() error { return svc.Service.Stop(ctx) }
+
+	if err := guard(g.qualify(svc.Name), "service stop panicked", stop); err != nil {
+		err = g.wrap(svc, "service stop failed", err)
+
+		g.transition(svc, StateFailed, err)
+		span.End(err)
+
+		return err
+	}
+
+	g.transition(svc, StateStopped, nil)
+	span.End(nil)
+
+	return nil
+}
+
+// wrap wraps err returned by svc into a GracefulError, attaching any output captured by the service. Errors returned
+// by child managers already carry hierarchical service names and are returned unchanged, as are recovered panics, which
+// only get the name of the service if they were recovered without it.
+func (g *Graceful) wrap(svc *ServiceDef, reason string, err error) error {
+	if _, ok := svc.Service.(*Graceful); ok {
+		return err
+	}
+
+	if perr, ok := err.(*GracefulError); ok && perr.Stack != nil {
+		if perr.Service == "" {
+			named := *perr
+			named.Service = g.qualify(svc.Name)
+
+			return &named
+		}
+
 		return err
-	default:
-		return nil
 	}
+
+	gerr := NewGracefulError(g.qualify(svc.Name), reason, err)
+	gerr.Output = outputOf(err)
+
+	return gerr
+}
+
+// qualify returns the hierarchical name of the named service, prefixed by the names of all parent managers.
+func (g *Graceful) qualify(name string) string {
+	for m := g; m.parent != nil; m = m.parent {
+		name = m.name + "/" + name
+	}
+
+	return name
+}
+
+// relative returns the hierarchical name of a service relative to g, as used by Inspect, given its name qualified from
+// the root manager, as carried by events.
+func (g *Graceful) relative(name string) string {
+	return strings.TrimPrefix(name, g.qualify(""))
 }
 
 // New creates a new Graceful manager.
-func New() *Graceful {
-	return &Graceful{svcs: make(Services)}
+func New(opts ...Option) *Graceful {
+	g := &Graceful{svcs: make(Services), failures: make(chan error, 1)}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
 }
@@ -2,7 +2,10 @@ package graceful_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -160,3 +163,162 @@ func TestGraceful_Stop(t *testing.T) {
 		}
 	})
 }
+
+type FailingSvc struct {
+	err error
+}
+
+func (f *FailingSvc) Start(ctx context.Context) error {
+	return f.err
+}
+
+func (f *FailingSvc) Stop(ctx context.Context) error {
+	return nil
+}
+
+func TestGraceful_Compose(t *testing.T) {
+	t.Run("Child manager starts and stops with parent", func(t *testing.T) {
+		events := make([]graceful.Event, 0)
+		mu := sync.Mutex{}
+
+		billing := graceful.New()
+		db := &MockSvc{name: "db"}
+		api := &MockSvc{name: "api"}
+
+		billing.Add("db", db)
+		billing.Add("api", api, "db")
+
+		root := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			events = append(events, ev)
+		}))
+		root.Add("billing", billing)
+
+		ctx := context.Background()
+		assert.NoError(t, root.Start(ctx))
+		assert.True(t, db.start)
+		assert.True(t, api.start)
+
+		state, ok := root.State("billing/db")
+		assert.True(t, ok)
+		assert.Equal(t, graceful.StateRunning, state)
+
+		states := root.States()
+		assert.Equal(t, graceful.StateRunning, states["billing"])
+		assert.Equal(t, graceful.StateRunning, states["billing/api"])
+
+		_, ok = root.State("billing/missing")
+		assert.False(t, ok)
+
+		assert.NoError(t, root.Stop(ctx))
+		assert.True(t, db.stop)
+		assert.True(t, api.stop)
+
+		state, _ = root.State("billing/api")
+		assert.Equal(t, graceful.StateStopped, state)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		names := make(map[string]bool)
+		for _, ev := range events {
+			names[ev.Service] = true
+		}
+
+		assert.True(t, names["billing"])
+		assert.True(t, names["billing/db"])
+		assert.True(t, names["billing/api"])
+	})
+
+	t.Run("Child errors carry hierarchical names", func(t *testing.T) {
+		billing := graceful.New()
+		billing.Add("db", &FailingSvc{err: errors.New("connection refused")})
+		billing.Add("api", &MockSvc{name: "api"}, "db")
+
+		root := graceful.New()
+		root.Add("billing", billing)
+
+		err := root.Start(context.Background())
+		assert.Error(t, err)
+
+		var gerr *graceful.GracefulError
+		assert.ErrorAs(t, err, &gerr)
+		assert.Equal(t, "billing/db", gerr.Service)
+
+		state, _ := root.State("billing/db")
+		assert.Equal(t, graceful.StateFailed, state)
+
+		state, _ = root.State("billing/api")
+		assert.Equal(t, graceful.StateStopped, state)
+	})
+
+	t.Run("Child manager that partly started is stopped", func(t *testing.T) {
+		db := &MockSvc{name: "db"}
+
+		billing := graceful.New()
+		billing.Add("db", db)
+		billing.Add("cache", &FailingSvc{err: errors.New("connection refused")})
+
+		root := graceful.New()
+		root.Add("billing", billing)
+
+		assert.Error(t, root.Start(context.Background()))
+		assert.NoError(t, root.Stop(context.Background()))
+
+		assert.True(t, db.stop)
+
+		state, _ := root.State("billing/db")
+		assert.Equal(t, graceful.StateStopped, state)
+	})
+}
+
+func TestGraceful_StartTwice(t *testing.T) {
+	t.Run("Start is rejected while running", func(t *testing.T) {
+		db := &MockSvc{name: "db"}
+
+		g := graceful.New()
+		g.Add("db", db)
+
+		ctx := context.Background()
+
+		assert.NoError(t, g.Start(ctx))
+		assert.ErrorIs(t, g.Start(ctx), graceful.ErrAlreadyStarted)
+		assert.NoError(t, g.Stop(ctx))
+		assert.True(t, db.stop)
+	})
+
+	t.Run("Start is rejected after a failed Start until Stop", func(t *testing.T) {
+		var starts, stops atomic.Int32
+
+		g := graceful.New()
+		g.Add("a", counted(&starts, &stops))
+		g.Add("b", &FailingSvc{err: errors.New("connection refused")})
+
+		ctx := context.Background()
+
+		assert.Error(t, g.Start(ctx))
+		assert.ErrorIs(t, g.Start(ctx), graceful.ErrAlreadyStarted)
+		assert.NoError(t, g.Stop(ctx))
+
+		assert.Error(t, g.Start(ctx))
+		assert.NoError(t, g.Stop(ctx))
+
+		assert.Equal(t, int32(2), starts.Load())
+		assert.Equal(t, int32(2), stops.Load())
+	})
+
+	t.Run("Start is allowed again after Stop", func(t *testing.T) {
+		g := graceful.New()
+		g.Add("db", graceful.FromFuncs(nil, nil))
+
+		ctx := context.Background()
+
+		assert.NoError(t, g.Start(ctx))
+		assert.NoError(t, g.Stop(ctx))
+		assert.NoError(t, g.Start(ctx))
+		assert.Equal(t, graceful.StateRunning, g.Status())
+		assert.NoError(t, g.Stop(ctx))
+	})
+}
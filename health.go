@@ -0,0 +1,270 @@
+package graceful
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// HealthChecker is implemented by services that can report whether they work correctly while running.
+	HealthChecker interface {
+		// Health returns an error if the service is unhealthy. It must return by the time ctx is done.
+		Health(ctx context.Context) error
+	}
+
+	// Health is the health of a service, or the aggregated health of a manager.
+	Health int
+
+	// HealthPolicy configures the health probes of a manager. Zero fields take their defaults.
+	HealthPolicy struct {
+		Interval         time.Duration // Time between probes of a service, 10s by default.
+		Timeout          time.Duration // Time a probe may take, 1s by default.
+		FailureThreshold int           // Consecutive failures after which a service is unhealthy, 3 by default.
+		SuccessThreshold int           // Consecutive successes after which it is healthy again, 1 by default.
+		Restart          bool          // Whether unhealthy services are restarted.
+	}
+
+	// Probe is the health of a service as observed by its probes.
+	Probe struct {
+		Health    Health    // Health derived from the probes.
+		Failures  int       // Consecutive failed probes.
+		Successes int       // Consecutive successful probes.
+		Err       error     // Error of the last failed probe.
+		Time      time.Time // Time of the last probe.
+	}
+
+	// prober probes the services of a manager implementing HealthChecker.
+	prober struct {
+		policy HealthPolicy
+		mu     sync.Mutex        // Guards the fields below.
+		probes map[string]*Probe // Probes of the services, by name.
+		cancel context.CancelFunc
+		wg     sync.WaitGroup // Tracks the probing goroutines.
+	}
+)
+
+const (
+	HealthHealthy   Health = iota // All probes pass.
+	HealthDegraded                // Probes fail, but not enough of them to be unhealthy.
+	HealthUnhealthy               // Probes failed FailureThreshold times in a row.
+)
+
+// String returns the name of the health.
+func (h Health) String() string {
+	switch h {
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	case HealthUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// WithHealthChecks makes the manager probe its services implementing HealthChecker according to p while it runs.
+//
+// Probes begin once Start succeeds and end when Stop begins; services are only probed while running. A service becomes
+// degraded on its first failed probe, unhealthy after FailureThreshold failed probes in a row, and healthy again after
+// SuccessThreshold successful ones. With Restart set, an unhealthy service is restarted; if the restart fails, the
+// service is failed and Run shuts down.
+func WithHealthChecks(p HealthPolicy) Option {
+	if p.Interval <= 0 {
+		p.Interval = 10 * time.Second
+	}
+
+	if p.Timeout <= 0 {
+		p.Timeout = time.Second
+	}
+
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = 3
+	}
+
+	if p.SuccessThreshold <= 0 {
+		p.SuccessThreshold = 1
+	}
+
+	return func(g *Graceful) {
+		g.prober = &prober{policy: p, probes: make(map[string]*Probe)}
+
+		g.observers = append(g.observers, func(ev Event) {
+			if ev.Service != "" {
+				return
+			}
+
+			switch ev.State {
+			case StateRunning:
+				g.prober.start(g)
+			case StateStopping, StateFailed:
+				g.prober.halt()
+			}
+		})
+	}
+}
+
+// Health returns the aggregated health of the services of g and of its child managers: unhealthy if any service is
+// unhealthy, degraded if any is degraded, and healthy otherwise.
+func (g *Graceful) Health() Health {
+	health := HealthHealthy
+
+	for _, probe := range g.Probes() {
+		health = max(health, probe.Health)
+	}
+
+	return health
+}
+
+// Probes returns the health of every probed service, including the services of child managers, keyed by hierarchical
+// name relative to g.
+func (g *Graceful) Probes() map[string]Probe {
+	probes := make(map[string]Probe)
+
+	if g.prober != nil {
+		g.prober.mu.Lock()
+		for name, probe := range g.prober.probes {
+			probes[name] = *probe
+		}
+		g.prober.mu.Unlock()
+	}
+
+	for name, svc := range g.svcs {
+		if child, ok := svc.Service.(*Graceful); ok {
+			for sub, probe := range child.Probes() {
+				probes[name+"/"+sub] = probe
+			}
+		}
+	}
+
+	return probes
+}
+
+// start begins probing the services of g, unless probes are already running.
+func (p *prober) start(g *Graceful) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	clear(p.probes)
+
+	for name, svc := range g.svcs {
+		checker, ok := svc.Service.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		p.probes[name] = &Probe{}
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+
+			ticker := time.NewTicker(p.policy.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					if g.state(name) == StateRunning {
+						p.probe(ctx, g, svc, checker)
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// halt stops probing, waiting for probes and restarts in progress.
+func (p *prober) halt() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		p.wg.Wait()
+	}
+}
+
+// probe checks the health of svc, restarting it if it became unhealthy and the policy says so.
+func (p *prober) probe(ctx context.Context, g *Graceful, svc *ServiceDef, checker HealthChecker) {
+	pctx, cancel := context.WithTimeout(ctx, p.policy.Timeout)
+	pctx, span := g.traceService(pctx, "graceful.service.health", svc)
+	err := guard(g.qualify(svc.Name), "health check panicked", func() error { return checker.Health(pctx) })
+	span.End(err)
+	cancel()
+
+	// Probes interrupted by halt say nothing about the service.
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !p.record(svc.Name, err) {
+		return
+	}
+
+	err = g.wrap(svc, "health check failed", err)
+
+	g.mu.Lock()
+	svc.err = err
+	g.mu.Unlock()
+
+	if !p.policy.Restart {
+		return
+	}
+
+	if err := g.restart(ctx, svc); err != nil {
+		g.notify(err)
+	}
+
+	p.mu.Lock()
+	p.probes[svc.Name] = &Probe{}
+	p.mu.Unlock()
+}
+
+// record updates the probe of the named service with the outcome of a probe, reporting whether the service just
+// became unhealthy.
+func (p *prober) record(name string, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	probe := p.probes[name]
+	probe.Time = time.Now()
+
+	if err == nil {
+		probe.Failures = 0
+		probe.Successes++
+
+		if probe.Successes >= p.policy.SuccessThreshold {
+			probe.Health = HealthHealthy
+		}
+
+		return false
+	}
+
+	probe.Successes = 0
+	probe.Failures++
+	probe.Err = err
+
+	switch {
+	case probe.Health == HealthUnhealthy:
+		return false
+	case probe.Failures >= p.policy.FailureThreshold:
+		probe.Health = HealthUnhealthy
+		return true
+	default:
+		probe.Health = HealthDegraded
+		return false
+	}
+}
@@ -0,0 +1,162 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+type HealthSvc struct {
+	starts  atomic.Int32
+	failing atomic.Bool // whether probes fail
+	probes  atomic.Int32
+}
+
+func (h *HealthSvc) Start(ctx context.Context) error {
+	h.starts.Add(1)
+	h.failing.Store(false)
+
+	return nil
+}
+
+func (h *HealthSvc) Stop(ctx context.Context) error { return nil }
+
+func (h *HealthSvc) Health(ctx context.Context) error {
+	h.probes.Add(1)
+
+	if h.failing.Load() {
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+// ScriptedHealth is a service whose probes return the results sent to it, in order.
+type ScriptedHealth struct {
+	results chan error
+}
+
+func (h *ScriptedHealth) Start(ctx context.Context) error { return nil }
+
+func (h *ScriptedHealth) Stop(ctx context.Context) error { return nil }
+
+func (h *ScriptedHealth) Health(ctx context.Context) error {
+	select {
+	case err := <-h.results:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// health waits until the named service of g reaches health.
+func health(t *testing.T, g *graceful.Graceful, name string, health graceful.Health) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool { return g.Probes()[name].Health == health }, time.Second, 5*time.Millisecond)
+}
+
+func TestGraceful_Health(t *testing.T) {
+	t.Run("Tracks consecutive failures and successes", func(t *testing.T) {
+		svc := &HealthSvc{}
+
+		g := graceful.New(graceful.WithHealthChecks(graceful.HealthPolicy{
+			Interval:         10 * time.Millisecond,
+			FailureThreshold: 3,
+			SuccessThreshold: 2,
+		}))
+		g.Add("db", svc)
+		g.Add("static", &MockSvc{name: "static"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		assert.Eventually(t, func() bool { return svc.probes.Load() > 0 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, graceful.HealthHealthy, g.Health())
+		assert.Len(t, g.Probes(), 1)
+
+		svc.failing.Store(true)
+		health(t, g, "db", graceful.HealthUnhealthy)
+		assert.Equal(t, graceful.HealthUnhealthy, g.Health())
+
+		probe := g.Probes()["db"]
+		assert.GreaterOrEqual(t, probe.Failures, 3)
+		assert.EqualError(t, probe.Err, "connection refused")
+
+		svc.failing.Store(false)
+		health(t, g, "db", graceful.HealthHealthy)
+		assert.GreaterOrEqual(t, g.Probes()["db"].Successes, 2)
+
+		// Unhealthy services are not restarted unless the policy says so.
+		assert.Equal(t, int32(1), svc.starts.Load())
+
+		require.NoError(t, g.Stop(ctx))
+
+		// Probes end with the manager.
+		probes := svc.probes.Load()
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, probes, svc.probes.Load())
+	})
+
+	t.Run("Degraded services recover after SuccessThreshold successes", func(t *testing.T) {
+		svc := &ScriptedHealth{results: make(chan error)}
+
+		g := graceful.New(graceful.WithHealthChecks(graceful.HealthPolicy{
+			Interval:         5 * time.Millisecond,
+			Timeout:          time.Minute,
+			FailureThreshold: 3,
+			SuccessThreshold: 2,
+		}))
+		g.Add("db", svc)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		svc.results <- errors.New("slow query")
+		health(t, g, "db", graceful.HealthDegraded)
+
+		svc.results <- nil
+		assert.Eventually(t, func() bool { return g.Probes()["db"].Successes == 1 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, graceful.HealthDegraded, g.Probes()["db"].Health)
+
+		svc.results <- nil
+		health(t, g, "db", graceful.HealthHealthy)
+
+		require.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Restarts unhealthy services", func(t *testing.T) {
+		svc := &HealthSvc{}
+
+		child := graceful.New(graceful.WithHealthChecks(graceful.HealthPolicy{
+			Interval:         10 * time.Millisecond,
+			FailureThreshold: 2,
+			Restart:          true,
+		}))
+		child.Add("db", svc)
+
+		g := graceful.New()
+		g.Add("billing", child)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		svc.failing.Store(true)
+		health(t, g, "billing/db", graceful.HealthDegraded)
+
+		assert.Eventually(t, func() bool { return svc.starts.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+		state, _ := g.State("billing/db")
+		assert.Equal(t, graceful.StateRunning, state)
+		assert.Equal(t, graceful.HealthHealthy, g.Health())
+
+		require.NoError(t, g.Stop(ctx))
+	})
+}
@@ -0,0 +1,164 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// HTTPServer is a Service serving an http.Server.
+	//
+	// The listener is bound during Start, so bind errors are reported as start failures. Stop shuts the server down,
+	// draining in-flight requests until the stop context is done, and then closes the remaining connections. Like
+	// http.Server, an HTTPServer cannot be restarted once stopped: Start then returns ErrServerStopped.
+	HTTPServer struct {
+		srv      *http.Server
+		tls      bool         // Whether to serve TLS, decided before net/http fills in srv.TLSConfig.
+		bind     binding      // Source of the listener.
+		inflight atomic.Int64 // Number of requests being handled.
+		mu       sync.Mutex   // Guards the fields below.
+		ln       net.Listener // Listener bound by Start.
+		done     chan error   // Receives the result of Serve.
+		fail     func(error)  // Reports a failure after Start, set by the manager.
+		stopped  bool         // Whether Stop shut srv down, so that it cannot serve again.
+	}
+)
+
+// ErrServerStopped is returned by HTTPServer.Start once the server has been stopped, as an http.Server cannot serve
+// again after it was shut down.
+var ErrServerStopped = errors.New("graceful: http server cannot be restarted once stopped")
+
+// NewHTTPServer creates a Service serving srv on srv.Addr, using TLS if srv.TLSConfig is set. The listener is taken
+// from a Listeners registry if configured with Listeners.Use.
+//
+// The handler of srv is wrapped to count in-flight requests, so srv must not be modified afterwards.
+func NewHTTPServer(srv *http.Server, opts ...ServerOption) *HTTPServer {
+	s := &HTTPServer{srv: srv, tls: srv.TLSConfig != nil}
+
+	for _, opt := range opts {
+		opt(&s.bind)
+	}
+
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inflight.Add(1)
+		defer s.inflight.Add(-1)
+
+		handler.ServeHTTP(w, r)
+	})
+
+	return s
+}
+
+// Addr returns the address the server is listening on, or nil if it is not started.
+func (s *HTTPServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return nil
+	}
+
+	return s.ln.Addr()
+}
+
+// InFlight returns the number of requests currently being handled.
+func (s *HTTPServer) InFlight() int64 {
+	return s.inflight.Load()
+}
+
+func (s *HTTPServer) supervise(_ string, _ *slog.Logger, fail func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+}
+
+// Start binds the listener and serves in the background. It returns ErrServerStopped if the server has been stopped.
+func (s *HTTPServer) Start(ctx context.Context) error {
+	s.mu.Lock()
+	stopped := s.stopped
+	s.mu.Unlock()
+
+	if stopped {
+		return ErrServerStopped
+	}
+
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+
+	ln, _, err := s.bind.listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+
+	s.mu.Lock()
+	s.ln, s.done = ln, done
+	s.mu.Unlock()
+
+	go func() {
+		var err error
+
+		if s.tls {
+			err = s.srv.ServeTLS(ln, "", "")
+		} else {
+			err = s.srv.Serve(ln)
+		}
+
+		done <- err
+
+		if errors.Is(err, http.ErrServerClosed) {
+			return
+		}
+
+		s.mu.Lock()
+		fail := s.fail
+		s.mu.Unlock()
+
+		if fail != nil {
+			fail(err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop shuts the server down, waiting for in-flight requests until ctx is done. If ctx is done first, the remaining
+// connections are closed and the number of requests still in flight is reported in the error.
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	done := s.done
+	s.done, s.stopped = nil, done != nil || s.stopped
+	s.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	if err := s.srv.Shutdown(ctx); err != nil {
+		remaining := s.inflight.Load()
+
+		return errors.Join(
+			fmt.Errorf("graceful: http server stopped with %d requests in flight: %w", remaining, err),
+			s.srv.Close(),
+		)
+	}
+
+	<-done
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package graceful_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestHTTPServer(t *testing.T) {
+	t.Run("Serves and drains", func(t *testing.T) {
+		srv := graceful.NewHTTPServer(&http.Server{
+			Addr: "127.0.0.1:0",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+				_, _ = io.WriteString(w, "ok")
+			}),
+		})
+
+		g := graceful.New()
+		g.Add("http", srv)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		result := make(chan string, 1)
+
+		go func() {
+			resp, err := http.Get("http://" + srv.Addr().String())
+			if err != nil {
+				result <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			result <- string(body)
+		}()
+
+		assert.Eventually(t, func() bool { return srv.InFlight() == 1 }, time.Second, 5*time.Millisecond)
+		assert.NoError(t, g.Stop(ctx))
+		assert.Equal(t, "ok", <-result)
+	})
+
+	t.Run("Bind error fails Start", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		g := graceful.New()
+		g.Add("http", graceful.NewHTTPServer(&http.Server{Addr: ln.Addr().String()}))
+
+		err = g.Start(context.Background())
+		assert.ErrorContains(t, err, "address already in use")
+	})
+
+	t.Run("Restart is rejected without leaving a listener behind", func(t *testing.T) {
+		srv := graceful.NewHTTPServer(&http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+
+		g := graceful.New()
+		g.Add("http", srv)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		addr := srv.Addr().String()
+
+		resp, err := http.Get("http://" + addr)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.ErrorIs(t, g.Restart(ctx, "http"), graceful.ErrServerStopped)
+
+		_, err = net.DialTimeout("tcp", addr, time.Second)
+		assert.Error(t, err, "nothing listens once the server stopped")
+
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Stop reports requests in flight at deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		srv := graceful.NewHTTPServer(&http.Server{
+			Addr: "127.0.0.1:0",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			}),
+		})
+
+		require.NoError(t, srv.Start(context.Background()))
+
+		go func() {
+			resp, err := http.Get("http://" + srv.Addr().String())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		assert.Eventually(t, func() bool { return srv.InFlight() == 1 }, time.Second, 5*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := srv.Stop(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "1 requests in flight")
+	})
+}
@@ -0,0 +1,316 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Listeners is a registry of named listening sockets.
+	//
+	// Sockets passed by systemd socket activation (LISTEN_FDS, LISTEN_PID and LISTEN_FDNAMES), or by a parent process
+	// during an upgrade, are inherited by name; listeners that were not passed are freshly bound. Server adapters take
+	// their listener from the registry when created with Use.
+	Listeners struct {
+		mu     sync.Mutex
+		files  map[string][]*os.File // Inherited sockets not yet listened on, by name.
+		claims map[string]bool       // Names claimed by server adapters.
+		active []*tracked            // Listeners in use, in the order they were created.
+		handed bool                  // Whether the listeners were handed off to another process.
+	}
+
+	// tracked is a listener of the registry, removed from it when closed.
+	tracked struct {
+		net.Listener
+		name string
+		l    *Listeners
+	}
+
+	// ServerOption configures the listener of an HTTPServer or TCPServer.
+	ServerOption func(*binding)
+
+	// binding describes where a server adapter takes its listener from.
+	binding struct {
+		listeners *Listeners // Registry, nil to always bind a fresh listener.
+		name      string     // Name of the listener in the registry.
+	}
+)
+
+const (
+	// listenFdsStart is the first file descriptor passed by systemd or by a parent process.
+	listenFdsStart = 3
+
+	// envListenNames names the sockets passed by a parent process during an upgrade.
+	envListenNames = "GRACEFUL_LISTEN_FDNAMES"
+)
+
+// NewListeners creates a registry holding the sockets passed by systemd socket activation or by an upgrading parent
+// process, if any. The environment variables describing them are unset so they are not passed on to child processes.
+func NewListeners() (*Listeners, error) {
+	l := &Listeners{files: make(map[string][]*os.File), claims: make(map[string]bool)}
+
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+		_ = os.Unsetenv(envListenNames)
+	}()
+
+	if names, ok := os.LookupEnv(envListenNames); ok {
+		if names != "" {
+			l.inherit(strings.Split(names, ":"))
+		}
+
+		return l, nil
+	}
+
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	if pid == "" || fds == "" {
+		return l, nil
+	}
+
+	if pid != strconv.Itoa(os.Getpid()) {
+		return l, nil
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("graceful: invalid LISTEN_FDS %q", fds)
+	}
+
+	var given []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		given = strings.Split(v, ":")
+	}
+
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "unknown"
+		if i < len(given) && given[i] != "" {
+			names[i] = given[i]
+		}
+	}
+
+	l.inherit(names)
+
+	return l, nil
+}
+
+// WithListeners makes Validate, and therefore Start, fail if l holds inherited sockets that no server adapter uses.
+func WithListeners(l *Listeners) Option {
+	return func(g *Graceful) {
+		g.validators = append(g.validators, l.validate)
+	}
+}
+
+// Use makes a server adapter take the listener named name from the registry.
+func (l *Listeners) Use(name string) ServerOption {
+	l.mu.Lock()
+	l.claims[name] = true
+	l.mu.Unlock()
+
+	return func(b *binding) {
+		b.listeners = l
+		b.name = name
+	}
+}
+
+// Listen returns the inherited socket named name if there is one, and otherwise binds a new listener on the given
+// network and address. If several sockets share a name, each call returns the next one.
+func (l *Listeners) Listen(ctx context.Context, name, network, address string) (net.Listener, error) {
+	ln, _, err := l.listen(ctx, name, network, address)
+
+	return ln, err
+}
+
+// Inherited returns the names of the inherited sockets that have not been listened on yet, sorted.
+func (l *Listeners) Inherited() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.files))
+	for name := range l.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// listen is Listen, also reporting whether the listener was inherited.
+func (l *Listeners) listen(ctx context.Context, name, network, address string) (net.Listener, bool, error) {
+	l.mu.Lock()
+
+	files := l.files[name]
+	if len(files) == 0 {
+		l.mu.Unlock()
+
+		ln, err := (&net.ListenConfig{}).Listen(ctx, network, address)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return l.track(name, ln), false, nil
+	}
+
+	file := files[0]
+	if len(files) == 1 {
+		delete(l.files, name)
+	} else {
+		l.files[name] = files[1:]
+	}
+	l.mu.Unlock()
+
+	// FileListener duplicates the descriptor, so the inherited one is closed either way.
+	defer file.Close()
+
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("graceful: inherited listener %s: %w", name, err)
+	}
+
+	return l.track(name, ln), true, nil
+}
+
+// inherit registers the sockets passed from descriptor 3 onwards under the given names.
+func (l *Listeners) inherit(names []string) {
+	for i, name := range names {
+		fd := uintptr(listenFdsStart + i)
+		l.files[name] = append(l.files[name], os.NewFile(fd, name))
+	}
+}
+
+// track records ln as in use until it is closed.
+func (l *Listeners) track(name string, ln net.Listener) net.Listener {
+	t := &tracked{Listener: ln, name: name, l: l}
+
+	l.mu.Lock()
+	l.active = append(l.active, t)
+	l.mu.Unlock()
+
+	return t
+}
+
+// handoff returns duplicates of the descriptors of the listeners in use and their names, to be passed to another
+// process. The caller must close the files.
+func (l *Listeners) handoff() ([]*os.File, []string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files := make([]*os.File, 0, len(l.active))
+	names := make([]string, 0, len(l.active))
+
+	for _, t := range l.active {
+		file, err := dup(t)
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+
+			return nil, nil, err
+		}
+
+		files = append(files, file)
+		names = append(names, t.name)
+	}
+
+	return files, names, nil
+}
+
+// release marks the listeners as handed off to another process, which now owns them. Unix sockets are no longer
+// removed when their listeners are closed.
+func (l *Listeners) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.handed = true
+
+	for _, t := range l.active {
+		if ul, ok := t.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+// dup duplicates the descriptor of t.
+func dup(t *tracked) (*os.File, error) {
+	ln, ok := t.Listener.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("graceful: listener %s cannot be handed off", t.name)
+	}
+
+	file, err := ln.File()
+	if err != nil {
+		return nil, fmt.Errorf("graceful: listener %s: %w", t.name, err)
+	}
+
+	return file, nil
+}
+
+// handedOff reports whether the listeners were handed off to another process.
+func (l *Listeners) handedOff() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.handed
+}
+
+// Close closes the listener and removes it from the registry.
+func (t *tracked) Close() error {
+	t.l.mu.Lock()
+
+	for i, active := range t.l.active {
+		if active == t {
+			t.l.active = append(t.l.active[:i], t.l.active[i+1:]...)
+			break
+		}
+	}
+	t.l.mu.Unlock()
+
+	return t.Listener.Close()
+}
+
+// validate reports inherited sockets that are not claimed by any server adapter.
+func (l *Listeners) validate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unused := make([]string, 0)
+
+	for name := range l.files {
+		if !l.claims[name] {
+			unused = append(unused, name)
+		}
+	}
+
+	if len(unused) == 0 {
+		return nil
+	}
+
+	sort.Strings(unused)
+
+	return fmt.Errorf("graceful: inherited listeners not used by any service: %s", strings.Join(unused, ", "))
+}
+
+// listen binds the listener of a server adapter.
+func (b *binding) listen(ctx context.Context, network, address string) (net.Listener, bool, error) {
+	if b.listeners == nil {
+		ln, err := (&net.ListenConfig{}).Listen(ctx, network, address)
+
+		return ln, false, err
+	}
+
+	return b.listeners.listen(ctx, b.name, network, address)
+}
+
+// released reports whether the listener was handed off to another process, which now owns it.
+func (b *binding) released() bool {
+	return b.listeners != nil && b.listeners.handedOff()
+}
@@ -0,0 +1,129 @@
+package graceful_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// TestListenersHelper runs in a child process started by TestListeners with an activated socket named "http".
+func TestListenersHelper(t *testing.T) {
+	mode := os.Getenv("GRACEFUL_LISTENERS_HELPER")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+
+	listeners, err := graceful.NewListeners()
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+
+	opts := make([]graceful.ServerOption, 0)
+	if mode == "use" {
+		opts = append(opts, listeners.Use("http"))
+	}
+
+	served := make(chan struct{})
+	srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", func(ctx context.Context, conn net.Conn) {
+		_, _ = io.WriteString(conn, "inherited\n")
+		close(served)
+	}, opts...)
+
+	g := graceful.New(graceful.WithListeners(listeners))
+	g.Add("tcp", srv)
+
+	if err := g.Start(context.Background()); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(0)
+	}
+
+	fmt.Println("started")
+	<-served
+
+	_ = g.Stop(context.Background())
+
+	os.Exit(0)
+}
+
+// activate starts the listeners helper in the given mode with ln passed as the activated socket "http", returning
+// the first line of its output.
+func activate(t *testing.T, ln *net.TCPListener, mode string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	file, err := ln.File()
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	// The shell sets LISTEN_PID to its own pid, which the test binary keeps through exec.
+	cmd := exec.Command("sh", "-c", `LISTEN_PID=$$ exec "$0" -test.run=^TestListenersHelper$`, os.Args[0])
+	cmd.Env = append(os.Environ(), "LISTEN_FDS=1", "LISTEN_FDNAMES=http", "GRACEFUL_LISTENERS_HELPER="+mode)
+	cmd.ExtraFiles = []*os.File{file}
+
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+
+	return line
+}
+
+func TestListeners(t *testing.T) {
+	t.Run("Binds when nothing is inherited", func(t *testing.T) {
+		listeners, err := graceful.NewListeners()
+		require.NoError(t, err)
+		assert.Empty(t, listeners.Inherited())
+
+		ln, err := listeners.Listen(context.Background(), "http", "tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		assert.NoError(t, ln.Close())
+	})
+
+	t.Run("Server takes the inherited socket", func(t *testing.T) {
+		ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+
+		assert.Equal(t, "started\n", activate(t, ln, "use"))
+
+		// Only the helper accepts on the socket from now on.
+		require.NoError(t, ln.Close())
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "inherited\n", line)
+	})
+
+	t.Run("Unused inherited socket fails validation", func(t *testing.T) {
+		ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+
+		defer ln.Close()
+
+		line := activate(t, ln, "ignore")
+		assert.Contains(t, line, "inherited listeners not used by any service: http")
+	})
+}
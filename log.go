@@ -0,0 +1,124 @@
+package graceful
+
+import (
+	"context"
+	"log/slog"
+)
+
+type (
+	// discard is a slog.Handler dropping all records.
+	discard struct{}
+)
+
+// WithLogger makes the manager, and child managers without a logger of their own, log every lifecycle transition to
+// l. A nil logger disables logging. Managers log to slog.Default if no logger is configured.
+//
+// Records carry the hierarchical service name, the phase the service entered, the time spent in the previous phase and
+// the error that caused the transition, if any, as the service, phase, duration and error attributes. Transitions of
+// the top-level manager itself are logged without a service.
+func WithLogger(l *slog.Logger) Option {
+	if l == nil {
+		l = slog.New(discard{})
+	}
+
+	return func(g *Graceful) {
+		g.logger = l
+	}
+}
+
+// WithLogLevel sets the level lifecycle transitions are logged at, slog.LevelInfo by default. Transitions caused by an
+// error are logged at slog.LevelError or above.
+func WithLogLevel(level slog.Leveler) Option {
+	return func(g *Graceful) {
+		g.level = level
+	}
+}
+
+// log returns the logger and level of g, or of its nearest parent configuring them.
+func (g *Graceful) log() (*slog.Logger, slog.Level) {
+	var (
+		logger *slog.Logger
+		level  slog.Leveler
+	)
+
+	for m := g; m != nil && (logger == nil || level == nil); m = m.parent {
+		if logger == nil {
+			logger = m.logger
+		}
+
+		if level == nil {
+			level = m.level
+		}
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	return logger, level.Level()
+}
+
+// record logs a lifecycle event of g.
+func (g *Graceful) record(ev Event) {
+	// Child managers are logged as services of their parent.
+	if ev.Service == "" && g.parent != nil {
+		return
+	}
+
+	logger, level := g.log()
+
+	logEvent(logger, level, ev)
+}
+
+// logEvent logs ev to logger at level, or at slog.LevelError if it carries an error.
+func logEvent(logger *slog.Logger, level slog.Level, ev Event) {
+	if ev.Err != nil {
+		level = max(level, slog.LevelError)
+	}
+
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	msg := "graceful: manager " + ev.State.String()
+	attrs := make([]slog.Attr, 0, 5)
+
+	if ev.Service != "" {
+		msg = "graceful: service " + ev.State.String()
+		attrs = append(attrs, slog.String("service", ev.Service))
+	}
+
+	attrs = append(attrs, slog.String("phase", ev.State.String()))
+
+	if ev.Duration > 0 {
+		attrs = append(attrs, slog.Duration("duration", ev.Duration))
+	}
+
+	if ev.Err != nil {
+		attrs = append(attrs, slog.Any("error", ev.Err))
+	}
+
+	// The stack of a panic is logged once, with the failure of the service that panicked.
+	if perr, ok := Panicked(ev.Err); ok && perr.Service == ev.Service {
+		attrs = append(attrs, slog.String("stack", string(perr.Stack)))
+	}
+
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// Enabled reports false for all levels.
+func (discard) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle drops the record.
+func (discard) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs returns the handler itself.
+func (d discard) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+// WithGroup returns the handler itself.
+func (d discard) WithGroup(string) slog.Handler { return d }
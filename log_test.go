@@ -0,0 +1,148 @@
+package graceful_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// records parses the JSON log records written to logs.
+func records(t *testing.T, logs *syncBuffer) []map[string]any {
+	t.Helper()
+
+	out := make([]map[string]any, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		record := make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		out = append(out, record)
+	}
+
+	return out
+}
+
+// find returns the first record with the given message and service.
+func find(records []map[string]any, msg, service string) map[string]any {
+	for _, r := range records {
+		if r["msg"] == msg && (service == "" || r["service"] == service) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Logs every transition with structured attributes", func(t *testing.T) {
+		logs := &syncBuffer{}
+
+		child := graceful.New()
+		child.Add("db", &MockSvc{name: "db"})
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("billing", child)
+		g.Add("cache", graceful.FromFuncs(nil, func(ctx context.Context) error { return errors.New("flush failed") }))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.Error(t, g.Stop(ctx))
+
+		recs := records(t, logs)
+
+		running := find(recs, "graceful: service running", "billing/db")
+		require.NotNil(t, running)
+		assert.Equal(t, "INFO", running["level"])
+		assert.Equal(t, "running", running["phase"])
+		assert.Contains(t, running, "duration")
+
+		failed := find(recs, "graceful: service failed", "cache")
+		require.NotNil(t, failed)
+		assert.Equal(t, "ERROR", failed["level"])
+		assert.Contains(t, failed["error"], "flush failed")
+
+		// The child manager is logged as a service of its parent only.
+		assert.NotNil(t, find(recs, "graceful: manager running", ""))
+		assert.NotNil(t, find(recs, "graceful: service running", "billing"))
+
+		managers := 0
+		for _, r := range recs {
+			if r["msg"] == "graceful: manager running" {
+				managers++
+			}
+		}
+
+		assert.Equal(t, 1, managers)
+	})
+
+	t.Run("Controls the level of routine records", func(t *testing.T) {
+		logs := &syncBuffer{}
+		logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+		g := graceful.New(graceful.WithLogger(logger), graceful.WithLogLevel(slog.LevelDebug))
+		g.Add("db", &MockSvc{name: "db"})
+		g.Add("cache", graceful.FromFuncs(func(ctx context.Context) error { return errors.New("refused") }, nil))
+
+		require.Error(t, g.Start(context.Background()))
+
+		recs := records(t, logs)
+		require.Len(t, recs, 2)
+		assert.Equal(t, "graceful: service failed", recs[0]["msg"])
+		assert.Equal(t, "graceful: manager failed", recs[1]["msg"])
+	})
+
+	t.Run("Logs v1 shutdowns", func(t *testing.T) {
+		logs := &syncBuffer{}
+		logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+		interrupt := make(chan any, 1)
+		cleanups := []graceful.Cleanup{
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return errors.New("close failed") },
+		}
+
+		code := graceful.Shutdown(context.Background(), cleanups, interrupt, time.Second, 0,
+			graceful.WithShutdownLogger(logger))
+		assert.Equal(t, 1, code)
+
+		recs := records(t, logs)
+		assert.NotNil(t, find(recs, "graceful: manager stopping", ""))
+		assert.NotNil(t, find(recs, "graceful: service stopped", "cleanup/0"))
+		assert.Contains(t, find(recs, "graceful: service failed", "cleanup/1")["error"], "close failed")
+		assert.Contains(t, find(recs, "graceful: manager failed", "")["error"], "close failed")
+	})
+
+	t.Run("Disables logging", func(t *testing.T) {
+		logs := &syncBuffer{}
+
+		previous := slog.Default()
+		slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+
+		defer slog.SetDefault(previous)
+
+		g := graceful.New(graceful.WithLogger(nil))
+		g.Add("db", &MockSvc{name: "db"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		interrupt := make(chan any, 1)
+		graceful.Shutdown(ctx, nil, interrupt, time.Second, 0, graceful.WithShutdownLogger(nil))
+
+		assert.Empty(t, logs.String())
+	})
+}
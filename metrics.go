@@ -0,0 +1,241 @@
+package graceful
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Metrics collects lifecycle metrics of a manager and serves them in the Prometheus text exposition format.
+	//
+	// Metrics is an http.Handler, usually mounted at /metrics. It exposes, per hierarchical service name:
+	//
+	//   - graceful_service_state, a gauge set to 1 for the current state of the service and 0 for the others.
+	//   - graceful_service_starts_total, graceful_service_stops_total, graceful_service_failures_total and
+	//     graceful_service_restarts_total, counters of lifecycle transitions.
+	//   - graceful_service_start_duration_seconds and graceful_service_stop_duration_seconds, histograms of the time
+	//     Start and Stop took, whether they succeeded or not.
+	Metrics struct {
+		buckets  []float64                  // Upper bounds of the histogram buckets, sorted.
+		mu       sync.Mutex                 // Guards the fields below.
+		g        *Graceful                  // Manager the metrics are collected from.
+		services map[string]*serviceMetrics // Metrics by hierarchical service name.
+	}
+
+	// serviceMetrics are the metrics of a single service.
+	serviceMetrics struct {
+		state    State // State of the last event, to tell starts from reloads.
+		starts   uint64
+		stops    uint64
+		failures uint64
+		start    histogram
+		stop     histogram
+	}
+
+	// histogram counts observations into buckets.
+	histogram struct {
+		counts []uint64 // Observations per bucket, not cumulative.
+		sum    float64
+		count  uint64
+	}
+)
+
+// DefaultBuckets are the histogram buckets used by NewMetrics if none are given, in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// NewMetrics creates a Metrics collector with the given histogram buckets in seconds, DefaultBuckets if none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Metrics{buckets: buckets, services: make(map[string]*serviceMetrics)}
+}
+
+// WithMetrics makes the manager feed its lifecycle events, and those of its child managers, to m.
+func WithMetrics(m *Metrics) Option {
+	return func(g *Graceful) {
+		m.mu.Lock()
+		m.g = g
+		m.mu.Unlock()
+
+		g.observers = append(g.observers, m.observe)
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = w.Write([]byte(m.format()))
+}
+
+// observe records a lifecycle event.
+func (m *Metrics) observe(ev Event) {
+	if ev.Service == "" {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := ev.Service
+	if m.g != nil {
+		name = m.g.relative(name)
+	}
+
+	sm := m.service(name)
+	previous := sm.state
+	sm.state = ev.State
+
+	switch ev.State {
+	case StateRunning:
+		if previous == StateStarting {
+			sm.starts++
+			sm.start.observe(m.buckets, ev.Duration.Seconds())
+		}
+	case StateStopped:
+		sm.stops++
+		sm.stop.observe(m.buckets, ev.Duration.Seconds())
+	case StateFailed:
+		sm.failures++
+
+		switch previous {
+		case StateStarting:
+			sm.start.observe(m.buckets, ev.Duration.Seconds())
+		case StateStopping:
+			sm.stop.observe(m.buckets, ev.Duration.Seconds())
+		}
+	}
+}
+
+// service returns the metrics of the named service, creating them if needed. The caller must hold m.mu.
+func (m *Metrics) service(name string) *serviceMetrics {
+	sm, ok := m.services[name]
+	if !ok {
+		sm = &serviceMetrics{}
+		m.services[name] = sm
+	}
+
+	return sm
+}
+
+// format renders the metrics of all services registered with the manager.
+func (m *Metrics) format() string {
+	m.mu.Lock()
+	g := m.g
+	m.mu.Unlock()
+
+	var statuses []ServiceStatus
+	if g != nil {
+		statuses = g.Inspect()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	header(&b, "graceful_service_state", "gauge", "Current lifecycle state of the service.")
+
+	for _, status := range statuses {
+		for state := StateStopped; state <= StateReloading; state++ {
+			value := 0
+			if status.State == state {
+				value = 1
+			}
+
+			fmt.Fprintf(&b, "graceful_service_state{service=%s,state=%q} %d\n", label(status.Name), state, value)
+		}
+	}
+
+	counters := []struct {
+		name, help string
+		value      func(ServiceStatus, *serviceMetrics) uint64
+	}{
+		{"graceful_service_starts_total", "Number of times the service started.",
+			func(_ ServiceStatus, sm *serviceMetrics) uint64 { return sm.starts }},
+		{"graceful_service_stops_total", "Number of times the service stopped.",
+			func(_ ServiceStatus, sm *serviceMetrics) uint64 { return sm.stops }},
+		{"graceful_service_failures_total", "Number of times the service failed.",
+			func(_ ServiceStatus, sm *serviceMetrics) uint64 { return sm.failures }},
+		{"graceful_service_restarts_total", "Number of times the service was restarted.",
+			func(status ServiceStatus, _ *serviceMetrics) uint64 { return uint64(status.Restarts) }},
+	}
+
+	for _, c := range counters {
+		header(&b, c.name, "counter", c.help)
+
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "%s{service=%s} %d\n", c.name, label(status.Name), c.value(status, m.service(status.Name)))
+		}
+	}
+
+	histograms := []struct {
+		name, help string
+		value      func(*serviceMetrics) *histogram
+	}{
+		{"graceful_service_start_duration_seconds", "Time the service took to start.",
+			func(sm *serviceMetrics) *histogram { return &sm.start }},
+		{"graceful_service_stop_duration_seconds", "Time the service took to stop.",
+			func(sm *serviceMetrics) *histogram { return &sm.stop }},
+	}
+
+	for _, h := range histograms {
+		header(&b, h.name, "histogram", h.help)
+
+		for _, status := range statuses {
+			h.value(m.service(status.Name)).format(&b, h.name, label(status.Name), m.buckets)
+		}
+	}
+
+	return b.String()
+}
+
+// observe records an observation of v.
+func (h *histogram) observe(buckets []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+
+	if i, _ := slices.BinarySearch(buckets, v); i < len(buckets) {
+		h.counts[i]++
+	}
+
+	h.sum += v
+	h.count++
+}
+
+// format renders the histogram for the service with the given quoted label.
+func (h *histogram) format(b *strings.Builder, name, service string, buckets []float64) {
+	var cumulative uint64
+
+	for i, le := range buckets {
+		if h.counts != nil {
+			cumulative += h.counts[i]
+		}
+
+		fmt.Fprintf(b, "%s_bucket{service=%s,le=%q} %d\n", name, service, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+	}
+
+	fmt.Fprintf(b, "%s_bucket{service=%s,le=\"+Inf\"} %d\n", name, service, h.count)
+	fmt.Fprintf(b, "%s_sum{service=%s} %s\n", name, service, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count{service=%s} %d\n", name, service, h.count)
+}
+
+// header writes the HELP and TYPE lines of a metric.
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// label quotes a label value as required by the text exposition format.
+func label(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
@@ -0,0 +1,83 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Exposes lifecycle metrics", func(t *testing.T) {
+		metrics := graceful.NewMetrics(0.01, 1)
+
+		child := graceful.New()
+		child.Add("db", graceful.FromFuncs(func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}, nil))
+
+		g := graceful.New(graceful.WithMetrics(metrics))
+		g.Add("billing", child)
+		g.Add("cache", graceful.FromFuncs(func(ctx context.Context) error { return errors.New("refused") }, nil))
+
+		ctx := context.Background()
+		require.Error(t, g.Start(ctx))
+		require.NoError(t, g.Restart(ctx, "billing/db"))
+		require.NoError(t, g.Stop(ctx))
+
+		code, body := get(t, metrics, "/metrics")
+		assert.Equal(t, http.StatusOK, code)
+
+		for _, line := range []string{
+			"# TYPE graceful_service_state gauge",
+			`graceful_service_state{service="billing/db",state="stopped"} 1`,
+			`graceful_service_state{service="billing/db",state="running"} 0`,
+			`graceful_service_state{service="cache",state="failed"} 1`,
+			"# TYPE graceful_service_starts_total counter",
+			`graceful_service_starts_total{service="billing/db"} 2`,
+			`graceful_service_stops_total{service="billing/db"} 2`,
+			`graceful_service_failures_total{service="cache"} 1`,
+			`graceful_service_restarts_total{service="billing/db"} 1`,
+			`graceful_service_restarts_total{service="cache"} 0`,
+			"# TYPE graceful_service_start_duration_seconds histogram",
+			`graceful_service_start_duration_seconds_bucket{service="billing/db",le="0.01"} 0`,
+			`graceful_service_start_duration_seconds_bucket{service="billing/db",le="1"} 2`,
+			`graceful_service_start_duration_seconds_bucket{service="billing/db",le="+Inf"} 2`,
+			`graceful_service_start_duration_seconds_count{service="billing/db"} 2`,
+			`graceful_service_start_duration_seconds_count{service="cache"} 1`,
+			`graceful_service_stop_duration_seconds_count{service="billing/db"} 2`,
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+	t.Run("Counts services of a child manager under names relative to it", func(t *testing.T) {
+		metrics := graceful.NewMetrics()
+
+		child := graceful.New(graceful.WithMetrics(metrics))
+		child.Add("db", graceful.FromFuncs(nil, nil))
+
+		g := graceful.New()
+		g.Add("billing", child)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		_, body := get(t, metrics, "/metrics")
+
+		for _, line := range []string{
+			`graceful_service_starts_total{service="db"} 1`,
+			`graceful_service_stops_total{service="db"} 1`,
+			`graceful_service_start_duration_seconds_count{service="db"} 1`,
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+}
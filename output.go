@@ -0,0 +1,145 @@
+package graceful
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+type (
+	// ring keeps the most recent lines written to it.
+	ring struct {
+		mu    sync.Mutex
+		lines []string
+		next  int  // Index the next line is written to.
+		full  bool // Whether lines has wrapped around.
+	}
+
+	// lines is an io.Writer splitting its input into lines, logging each and recording it in a ring.
+	lines struct {
+		stream  string       // Stream name, "stdout" or "stderr".
+		service string       // Service name.
+		logger  *slog.Logger // Logger lines are forwarded to.
+		ring    *ring        // Ring lines are recorded in.
+		mu      sync.Mutex   // Guards buf.
+		buf     []byte       // Incomplete last line.
+	}
+
+	// outputError wraps an error of a service with the output it captured.
+	outputError struct {
+		err    error
+		output []string
+	}
+)
+
+// maxLine is the length at which an unterminated line is split.
+const maxLine = 64 * 1024
+
+// newRing creates a ring keeping size lines, none if size is not positive.
+func newRing(size int) *ring {
+	return &ring{lines: make([]string, max(size, 0))}
+}
+
+// add records line, evicting the oldest line if the ring is full.
+func (r *ring) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.lines) == 0 {
+		return
+	}
+
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// tail returns the recorded lines, oldest first.
+func (r *ring) tail() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// Write records every complete line in p and buffers the rest.
+func (l *lines) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, p...)
+
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		l.emit(bytes.TrimSuffix(l.buf[:i], []byte{'\r'}))
+		l.buf = l.buf[i+1:]
+	}
+
+	for len(l.buf) >= maxLine {
+		l.emit(l.buf[:maxLine])
+		l.buf = l.buf[maxLine:]
+	}
+
+	return len(p), nil
+}
+
+// flush records the incomplete last line, if any.
+func (l *lines) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.buf) > 0 {
+		l.emit(l.buf)
+		l.buf = nil
+	}
+}
+
+func (l *lines) emit(line []byte) {
+	text := string(line)
+
+	l.ring.add(l.stream + ": " + text)
+
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, text,
+		slog.String("service", l.service), slog.String("stream", l.stream),
+	)
+}
+
+func (e *outputError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outputError) Unwrap() error {
+	return e.err
+}
+
+// withOutput attaches output to err. It returns err unchanged if err is nil or there is no output.
+func withOutput(err error, output []string) error {
+	if err == nil || len(output) == 0 {
+		return err
+	}
+
+	return &outputError{err: err, output: output}
+}
+
+// outputOf returns the output attached to err, if any.
+func outputOf(err error) []string {
+	var oerr *outputError
+	if errors.As(err, &oerr) {
+		return oerr.output
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package graceful
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// WithRepanic makes Run panic again with the first recovered panic once it has shut down, so the process still crashes
+// with a non-zero exit status and a crash report, after every other service stopped cleanly.
+//
+// Panics in Start, Stop and Reload of services, in health checks, in the goroutines of Runner services and of services
+// built by FromParameterized, FromInterruptable and FromBlocking, and in TCPServer connection handlers are always
+// recovered by the manager. They are turned into a GracefulError carrying the panic value and the stack of the
+// goroutine that panicked, and handled like any other error: a panic in Start fails Start, and a panic of a running
+// service makes Run shut down. The stack is logged with the failure and written to the termination message.
+func WithRepanic() Option {
+	return func(g *Graceful) {
+		g.repanic = true
+	}
+}
+
+// Panicked returns the first GracefulError in the tree of err that was recovered from a panic.
+func Panicked(err error) (*GracefulError, bool) {
+	if gerr, ok := err.(*GracefulError); ok && gerr.Stack != nil {
+		return gerr, true
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range e.Unwrap() {
+			if gerr, ok := Panicked(child); ok {
+				return gerr, true
+			}
+		}
+	case interface{ Unwrap() error }:
+		return Panicked(e.Unwrap())
+	}
+
+	return nil, false
+}
+
+// guard calls fn, turning a panic into a GracefulError for the named service with the given reason. The name may be
+// empty when it is not known yet, see Graceful.wrap.
+func guard(service, reason string, fn func() error) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			cause, ok := value.(error)
+			if !ok {
+				cause = errors.New(fmt.Sprint(value))
+			}
+
+			err = &GracefulError{Service: service, Reason: reason, Err: cause, Panic: value, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
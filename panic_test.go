@@ -0,0 +1,171 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestGraceful_Panics(t *testing.T) {
+	t.Run("Panic in Start fails Start", func(t *testing.T) {
+		logs := &syncBuffer{}
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("db", graceful.FromFuncs(func(context.Context) error { panic("boom") }, nil))
+
+		err := g.Start(context.Background())
+		require.Error(t, err)
+
+		perr, ok := graceful.Panicked(err)
+		require.True(t, ok)
+		assert.Equal(t, "db", perr.Service)
+		assert.Equal(t, "boom", perr.Panic)
+		assert.Contains(t, string(perr.Stack), "panic_test.go")
+		assert.Contains(t, err.Error(), "service start panicked: boom")
+
+		state, _ := g.State("db")
+		assert.Equal(t, graceful.StateFailed, state)
+
+		failed := find(records(t, logs), "graceful: service failed", "db")
+		require.NotNil(t, failed)
+		assert.Contains(t, failed["stack"], "panic_test.go")
+	})
+
+	t.Run("Panic in Stop does not keep other services from stopping", func(t *testing.T) {
+		db := &MockSvc{name: "db"}
+
+		g := graceful.New()
+		g.Add("db", db)
+		g.Add("api", graceful.FromFuncs(nil, func(context.Context) error { panic("stuck") }), "db")
+
+		require.NoError(t, g.Start(context.Background()))
+
+		err := g.Stop(context.Background())
+		require.Error(t, err)
+
+		perr, ok := graceful.Panicked(err)
+		require.True(t, ok)
+		assert.Equal(t, "api", perr.Service)
+		assert.True(t, db.stop)
+	})
+
+	t.Run("Panic of a runner makes Run shut down", func(t *testing.T) {
+		exploded := errors.New("exploded")
+		trigger := make(chan struct{})
+		db := &MockSvc{name: "db"}
+
+		g := graceful.New()
+		g.Add("db", db)
+		g.Add("worker", graceful.NewRunner(graceful.Blocking(func(ctx context.Context) error {
+			<-trigger
+			panic(exploded)
+		})), "db")
+
+		done := make(chan error, 1)
+
+		go func() { done <- g.Run(context.Background(), time.Second) }()
+
+		require.Eventually(t, func() bool {
+			state, _ := g.State("worker")
+			return state == graceful.StateRunning
+		}, time.Second, 5*time.Millisecond)
+
+		close(trigger)
+
+		err := <-done
+		require.Error(t, err)
+		assert.ErrorIs(t, err, exploded)
+
+		perr, ok := graceful.Panicked(err)
+		require.True(t, ok)
+		assert.Equal(t, "worker", perr.Service)
+		assert.True(t, db.stop)
+	})
+
+	t.Run("Panic of an adapter while running makes Run shut down", func(t *testing.T) {
+		db := &MockSvc{name: "db"}
+
+		g := graceful.New()
+		g.Add("db", db)
+		g.Add("worker", graceful.FromBlocking(func(ctx context.Context) error { panic("lost") }), "db")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := g.Run(ctx, time.Second)
+		require.Error(t, err)
+		require.NoError(t, ctx.Err(), "Run shuts down on the panic, not on its context")
+
+		perr, ok := graceful.Panicked(err)
+		require.True(t, ok)
+		assert.Equal(t, "worker", perr.Service)
+		assert.Equal(t, "lost", perr.Panic)
+		assert.True(t, db.stop)
+	})
+
+	t.Run("Panic of an adapter while stopping is returned by Stop", func(t *testing.T) {
+		g := graceful.New()
+		g.Add("worker", graceful.FromInterruptable(func(release <-chan any) error {
+			<-release
+			panic("lost")
+		}))
+
+		require.NoError(t, g.Start(context.Background()))
+
+		err := g.Stop(context.Background())
+		require.Error(t, err)
+
+		perr, ok := graceful.Panicked(err)
+		require.True(t, ok)
+		assert.Equal(t, "worker", perr.Service)
+	})
+
+	t.Run("Panics of child managers keep their hierarchical name", func(t *testing.T) {
+		child := graceful.New()
+		child.Add("db", graceful.FromFuncs(func(context.Context) error { panic("boom") }, nil))
+
+		g := graceful.New()
+		g.Add("billing", child)
+
+		perr, ok := graceful.Panicked(g.Start(context.Background()))
+		require.True(t, ok)
+		assert.Equal(t, "billing/db", perr.Service)
+	})
+}
+
+func TestWithRepanic(t *testing.T) {
+	t.Run("Run panics again once it has shut down", func(t *testing.T) {
+		db := &MockSvc{name: "db"}
+
+		g := graceful.New(graceful.WithRepanic())
+		g.Add("db", db)
+		g.Add("api", graceful.FromFuncs(func(context.Context) error { panic("boom") }, nil), "db")
+
+		var value any
+
+		func() {
+			defer func() { value = recover() }()
+
+			_ = g.Run(context.Background(), time.Second)
+		}()
+
+		perr, ok := value.(*graceful.GracefulError)
+		require.True(t, ok, "Run panics with the recovered GracefulError")
+		assert.Equal(t, "boom", perr.Panic)
+		assert.True(t, db.stop, "services are stopped before Run panics")
+	})
+
+	t.Run("Run returns errors that are not panics", func(t *testing.T) {
+		g := graceful.New(graceful.WithRepanic())
+		g.Add("db", &FailingSvc{err: errors.New("connection refused")})
+
+		assert.NotPanics(t, func() { assert.Error(t, g.Run(context.Background(), time.Second)) })
+	})
+}
@@ -0,0 +1,82 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// Reloadable is implemented by services that can reload their configuration without restarting.
+	Reloadable interface {
+		// Reload reloads the configuration of the service. If it fails, the service must keep running with its previous
+		// configuration.
+		Reload(ctx context.Context) error
+	}
+)
+
+// Reload reloads all running services implementing Reloadable, one at a time in dependency order. Child managers
+// reload their own services.
+//
+// A service that fails to reload keeps running; the errors of all services are returned together.
+func (g *Graceful) Reload(ctx context.Context) error {
+	g.mu.RLock()
+	status := g.status
+	g.mu.RUnlock()
+
+	ctx, span := g.trace(ctx, "graceful.reload")
+
+	g.advance(StateReloading, nil)
+
+	err := g.reload(ctx)
+
+	g.advance(status, err)
+	span.End(err)
+
+	return err
+}
+
+// reload reloads the services, see Reload.
+func (g *Graceful) reload(ctx context.Context) error {
+	sorted, err := g.sort()
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	for _, name := range sorted {
+		if err := g.reloadService(ctx, g.svcs[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reloadService reloads a single service if it is running and implements Reloadable.
+func (g *Graceful) reloadService(ctx context.Context, svc *ServiceDef) error {
+	reloadable, ok := svc.Service.(Reloadable)
+	if !ok || g.state(svc.Name) != StateRunning {
+		return nil
+	}
+
+	ctx, span := g.traceService(ctx, "graceful.service.reload", svc)
+
+	g.transition(svc, StateReloading, nil)
+
+	reload := func() error { return reloadable.Reload(ctx) }
+
+	if err := guard(g.qualify(svc.Name), "service reload panicked", reload); err != nil {
+		err = g.wrap(svc, "service reload failed", err)
+
+		g.transition(svc, StateRunning, err)
+		span.End(err)
+
+		return err
+	}
+
+	g.transition(svc, StateRunning, nil)
+	span.End(nil)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+type ConfigSvc struct {
+	MockSvc
+	err     error       // error returned by Reload, if any
+	order   *[]string   // records the order of reloads
+	mu      *sync.Mutex // guards order
+	version int
+}
+
+func (c *ConfigSvc) Reload(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	*c.order = append(*c.order, c.name)
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.version++
+
+	return nil
+}
+
+func TestGraceful_Reload(t *testing.T) {
+	t.Run("Reloads in dependency order and aggregates errors", func(t *testing.T) {
+		order := make([]string, 0)
+		mu := &sync.Mutex{}
+
+		db := &ConfigSvc{MockSvc: MockSvc{name: "db"}, order: &order, mu: mu}
+		cache := &ConfigSvc{MockSvc: MockSvc{name: "cache"}, order: &order, mu: mu, err: errors.New("bad config")}
+		api := &ConfigSvc{MockSvc: MockSvc{name: "api"}, order: &order, mu: mu}
+
+		g := graceful.New()
+		g.Add("db", db)
+		g.Add("cache", cache, "db")
+		g.Add("api", api, "cache")
+		g.Add("static", &MockSvc{name: "static"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		err := g.Reload(ctx)
+
+		var gerr *graceful.GracefulError
+		require.ErrorAs(t, err, &gerr)
+		assert.Equal(t, "cache", gerr.Service)
+
+		assert.Equal(t, []string{"db", "cache", "api"}, order)
+		assert.Equal(t, 1, db.version)
+		assert.Equal(t, 0, cache.version)
+		assert.Equal(t, 1, api.version)
+
+		// The failed service keeps running with its old config.
+		state, _ := g.State("cache")
+		assert.Equal(t, graceful.StateRunning, state)
+
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Reloads child managers", func(t *testing.T) {
+		order := make([]string, 0)
+		db := &ConfigSvc{MockSvc: MockSvc{name: "db"}, order: &order, mu: &sync.Mutex{}}
+
+		billing := graceful.New()
+		billing.Add("db", db)
+
+		g := graceful.New()
+		g.Add("billing", billing)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Reload(ctx))
+		assert.Equal(t, 1, db.version)
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Run reloads on SIGHUP", func(t *testing.T) {
+		order := make([]string, 0)
+		reloaded := make(chan struct{})
+		db := &ConfigSvc{MockSvc: MockSvc{name: "db"}, order: &order, mu: &sync.Mutex{}}
+
+		g := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.Service == "" && ev.State == graceful.StateRunning && len(order) > 0 {
+				close(reloaded)
+			}
+		}))
+		g.Add("db", db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+
+		go func() { done <- g.Run(ctx, time.Second) }()
+
+		require.Eventually(t, func() bool {
+			state, _ := g.State("db")
+			return state == graceful.StateRunning
+		}, time.Second, 5*time.Millisecond)
+
+		self, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, self.Signal(syscall.SIGHUP))
+
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for reload")
+		}
+
+		cancel()
+
+		assert.NoError(t, <-done)
+		assert.Equal(t, 1, db.version)
+	})
+}
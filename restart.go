@@ -0,0 +1,43 @@
+package graceful
+
+import (
+	"context"
+	"slices"
+)
+
+// Restart stops the named service and starts it again. The name may be hierarchical, e.g. "billing/db".
+//
+// Services depending on the restarted service keep running while it restarts. A service that was not started, or
+// failed to start, is only started.
+func (g *Graceful) Restart(ctx context.Context, name string) error {
+	m, svc, ok := g.lookup(name)
+	if !ok {
+		return NewGracefulError(g.qualify(name), "service not found", nil)
+	}
+
+	return m.restart(ctx, svc)
+}
+
+// restart restarts a single service of g, see Restart.
+func (g *Graceful) restart(ctx context.Context, svc *ServiceDef) (err error) {
+	ctx, span := g.traceService(ctx, "graceful.restart", svc)
+	defer func() { span.End(err) }()
+
+	g.mu.RLock()
+	started := slices.Contains(g.order, svc.Name)
+	g.mu.RUnlock()
+
+	g.journal(auditRecord{Kind: "restart", Service: g.qualify(svc.Name)})
+
+	if started {
+		if err := g.stopService(ctx, svc); err != nil {
+			return err
+		}
+	}
+
+	g.mu.Lock()
+	svc.restarts++
+	g.mu.Unlock()
+
+	return g.startService(ctx, svc)
+}
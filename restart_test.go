@@ -0,0 +1,57 @@
+package graceful_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// counted returns a service counting its starts and stops.
+func counted(starts, stops *atomic.Int32) graceful.Service {
+	return graceful.FromFuncs(
+		func(ctx context.Context) error { starts.Add(1); return nil },
+		func(ctx context.Context) error { stops.Add(1); return nil },
+	)
+}
+
+func TestGraceful_Restart(t *testing.T) {
+	t.Run("Restarts a service of a child manager", func(t *testing.T) {
+		var starts, stops, others atomic.Int32
+
+		child := graceful.New()
+		child.Add("db", counted(&starts, &stops))
+
+		g := graceful.New()
+		g.Add("billing", child)
+		g.Add("api", counted(&others, &others), "billing")
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		require.NoError(t, g.Restart(ctx, "billing/db"))
+		assert.Equal(t, int32(2), starts.Load())
+		assert.Equal(t, int32(1), stops.Load())
+
+		// Dependents are left alone.
+		assert.Equal(t, int32(1), others.Load())
+
+		state, _ := g.State("billing/db")
+		assert.Equal(t, graceful.StateRunning, state)
+
+		require.NoError(t, g.Stop(ctx))
+		assert.Equal(t, int32(2), stops.Load())
+	})
+
+	t.Run("Fails for unknown services", func(t *testing.T) {
+		g := graceful.New()
+
+		var gerr *graceful.GracefulError
+		require.ErrorAs(t, g.Restart(context.Background(), "missing"), &gerr)
+		assert.Equal(t, "missing", gerr.Service)
+	})
+}
@@ -0,0 +1,118 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type (
+	// trigger handles a signal received by the run loop. handle reports whether the run loop should stop.
+	trigger struct {
+		signal os.Signal
+		handle func(ctx context.Context) (bool, error)
+	}
+)
+
+// Run starts all services and blocks until ctx is done, SIGINT, SIGTERM or SIGQUIT is received, or a service fails
+// after it started. It then stops all services, giving them timeout to stop. While running, SIGHUP reloads all
+// services.
+//
+// Run returns the errors of Start and Stop, and the failure that caused the shutdown, if any. Other signals are handled
+// by options such as WithUpgrader. With WithTerminationLog, the outcome is summarised in a termination message before
+// Run returns. With WithRepanic, Run panics instead of returning if a service panicked.
+func (g *Graceful) Run(ctx context.Context, timeout time.Duration) error {
+	reload := trigger{
+		signal: syscall.SIGHUP,
+		handle: func(ctx context.Context) (bool, error) { return false, g.Reload(ctx) },
+	}
+
+	triggers := append([]trigger{reload}, g.triggers...)
+
+	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
+	for _, t := range triggers {
+		signals = append(signals, t.signal)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+
+	defer signal.Stop(sigs)
+
+	// Discard failures from a previous run.
+	select {
+	case <-g.failures:
+	default:
+	}
+
+	// Audit records are flushed before the process gets a chance to exit.
+	defer g.flush()
+
+	cause, err := "start failed", g.Start(ctx)
+	if err == nil {
+		cause, err = g.wait(ctx, sigs, triggers)
+	}
+
+	err = errors.Join(err, g.shutdown(ctx, timeout))
+
+	g.terminate(cause, err)
+
+	if perr, ok := Panicked(err); ok && g.repanic {
+		g.flush()
+		panic(perr)
+	}
+
+	return err
+}
+
+// wait blocks until the run loop should stop, returning why, and the failure that caused it, if any.
+func (g *Graceful) wait(ctx context.Context, sigs <-chan os.Signal, triggers []trigger) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err().Error(), nil
+		case err := <-g.failures:
+			return "service failed", err
+		case sig := <-sigs:
+			g.journal(auditRecord{Kind: "signal", Signal: sig.String()})
+			g.flush()
+
+			t, ok := find(triggers, sig)
+			if !ok {
+				return "received signal " + sig.String(), nil
+			}
+
+			stop, err := t.handle(ctx)
+			if err != nil {
+				logger, _ := g.log()
+				logger.Warn("graceful: signal handler failed", "signal", sig.String(), "error", err)
+			}
+
+			if stop {
+				return "received signal " + sig.String(), nil
+			}
+		}
+	}
+}
+
+// find returns the trigger handling sig.
+func find(triggers []trigger, sig os.Signal) (trigger, bool) {
+	for _, t := range triggers {
+		if t.signal == sig {
+			return t, true
+		}
+	}
+
+	return trigger{}, false
+}
+
+// shutdown stops all services, giving them timeout to stop.
+func (g *Graceful) shutdown(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	return g.Stop(ctx)
+}
@@ -0,0 +1,190 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+type (
+	// Runner is a long-running service that blocks until its context is cancelled, e.g. a worker loop or a server.
+	Runner interface {
+		// Run runs the service until ctx is cancelled.
+		Run(ctx context.Context) error
+	}
+
+	// RunnerOption configures a Runner service.
+	RunnerOption func(*runner)
+
+	// runner is a Service that runs a Runner in a supervised goroutine.
+	runner struct {
+		Runner
+		ready    bool               // Whether Start waits for Ready to be called.
+		mu       sync.Mutex         // Guards the fields below.
+		cancel   context.CancelFunc // Cancels the context passed to Run.
+		done     chan struct{}      // Closed when Run returns.
+		err      error              // Error returned by Run.
+		started  bool               // Whether Start returned successfully.
+		stopping bool               // Whether Stop has been called.
+		exited   bool               // Whether Run has returned.
+		fail     func(error)        // Reports a failure after Start, set by the manager.
+		name     string             // Hierarchical name of the service, set by the manager.
+	}
+
+	// supervised is implemented by services that can fail after Start has returned. The manager calls supervise before
+	// Start with the hierarchical name of the service, the logger of the manager and a function reporting such failures.
+	supervised interface {
+		supervise(name string, logger *slog.Logger, fail func(error))
+	}
+
+	// readyKey is the context key for the readiness callback of a Runner.
+	readyKey struct{}
+)
+
+// ErrRunnerExited is reported when a Runner returns without an error before it was stopped.
+var ErrRunnerExited = errors.New("graceful: runner exited unexpectedly")
+
+// Run runs fn, making Blocking a Runner.
+func (fn Blocking) Run(ctx context.Context) error {
+	return fn(ctx)
+}
+
+// WaitReady makes Start wait until the runner calls Ready with its context, instead of treating the runner as ready as
+// soon as it is launched.
+func WaitReady() RunnerOption {
+	return func(r *runner) {
+		r.ready = true
+	}
+}
+
+// NewRunner creates a Service that runs r in a goroutine.
+//
+// Start launches Run and returns once the runner is ready. Stop cancels the context passed to Run and waits for it to
+// return. If Run returns before Stop is called, the manager marks the service as failed.
+func NewRunner(r Runner, opts ...RunnerOption) Service {
+	svc := &runner{Runner: r}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	return svc
+}
+
+// Ready marks the Runner running with ctx as ready. It is a no-op if ctx was not passed to Run by a Runner service.
+func Ready(ctx context.Context) {
+	if fn, ok := ctx.Value(readyKey{}).(func()); ok {
+		fn()
+	}
+}
+
+func (r *runner) supervise(name string, _ *slog.Logger, fail func(error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.name, r.fail = name, fail
+}
+
+func (r *runner) Start(ctx context.Context) error {
+	var once sync.Once
+
+	ready := make(chan struct{})
+	done := make(chan struct{})
+
+	run, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run = context.WithValue(run, readyKey{}, func() { once.Do(func() { close(ready) }) })
+
+	r.mu.Lock()
+	r.cancel, r.done, r.err = cancel, done, nil
+	r.started, r.stopping, r.exited = false, false, false
+	name := r.name
+	r.mu.Unlock()
+
+	go func() {
+		err := guard(name, "runner panicked", func() error { return r.Run(run) })
+
+		r.mu.Lock()
+		r.err, r.exited = err, true
+		report := r.started && !r.stopping
+		fail := r.fail
+		r.mu.Unlock()
+
+		close(done)
+
+		if report && fail != nil {
+			if err == nil {
+				err = ErrRunnerExited
+			}
+
+			fail(err)
+		}
+	}()
+
+	if r.ready {
+		select {
+		case <-ready:
+		case <-done:
+		case <-ctx.Done():
+			r.mu.Lock()
+			r.stopping = true
+			r.mu.Unlock()
+
+			cancel()
+
+			return ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exited {
+		cancel()
+
+		if r.err == nil {
+			return ErrRunnerExited
+		}
+
+		return r.err
+	}
+
+	r.started = true
+
+	return nil
+}
+
+func (r *runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+
+	if r.done == nil || r.stopping {
+		r.mu.Unlock()
+		return nil
+	}
+
+	// A runner that exited on its own has already been reported as failed.
+	exited := r.exited
+	done := r.done
+	r.stopping = true
+	r.cancel()
+	r.mu.Unlock()
+
+	if exited {
+		return nil
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if errors.Is(r.err, context.Canceled) {
+		return nil
+	}
+
+	return r.err
+}
@@ -0,0 +1,119 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/graceful"
+)
+
+type Worker struct {
+	delay time.Duration // delay before signalling readiness
+	exit  error         // error to return before being stopped, if any
+	mu    sync.Mutex
+	runs  int
+}
+
+func (w *Worker) Run(ctx context.Context) error {
+	w.mu.Lock()
+	w.runs++
+	w.mu.Unlock()
+
+	time.Sleep(w.delay)
+	graceful.Ready(ctx)
+
+	if w.exit != nil {
+		return w.exit
+	}
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("Start waits for readiness", func(t *testing.T) {
+		w := &Worker{delay: 100 * time.Millisecond}
+
+		g := graceful.New()
+		g.Add("worker", graceful.NewRunner(w, graceful.WaitReady()))
+
+		ctx := context.Background()
+		begin := time.Now()
+
+		assert.NoError(t, g.Start(ctx))
+		assert.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+
+		state, _ := g.State("worker")
+		assert.Equal(t, graceful.StateRunning, state)
+
+		assert.NoError(t, g.Stop(ctx))
+
+		state, _ = g.State("worker")
+		assert.Equal(t, graceful.StateStopped, state)
+	})
+
+	t.Run("Exit before readiness fails Start", func(t *testing.T) {
+		runner := graceful.NewRunner(graceful.Blocking(func(ctx context.Context) error {
+			return errors.New("bind failed")
+		}), graceful.WaitReady())
+
+		g := graceful.New()
+		g.Add("worker", runner)
+
+		err := g.Start(context.Background())
+		assert.ErrorContains(t, err, "bind failed")
+	})
+
+	t.Run("Unexpected return marks the service failed", func(t *testing.T) {
+		failed := make(chan graceful.Event, 1)
+
+		g := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.State == graceful.StateFailed {
+				failed <- ev
+			}
+		}))
+		g.Add("worker", graceful.NewRunner(&Worker{delay: 50 * time.Millisecond, exit: errors.New("lost lease")}))
+
+		ctx := context.Background()
+		assert.NoError(t, g.Start(ctx))
+
+		select {
+		case ev := <-failed:
+			assert.Equal(t, "worker", ev.Service)
+			assert.ErrorContains(t, ev.Err, "lost lease")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for failure")
+		}
+
+		assert.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Nil return before Stop is a failure", func(t *testing.T) {
+		failed := make(chan graceful.Event, 1)
+
+		g := graceful.New(graceful.WithObserver(func(ev graceful.Event) {
+			if ev.State == graceful.StateFailed {
+				failed <- ev
+			}
+		}))
+		g.Add("worker", graceful.NewRunner(graceful.Blocking(func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})))
+
+		assert.NoError(t, g.Start(context.Background()))
+
+		select {
+		case ev := <-failed:
+			assert.ErrorIs(t, ev.Err, graceful.ErrRunnerExited)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for failure")
+		}
+	})
+}
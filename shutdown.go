@@ -0,0 +1,67 @@
+package graceful
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// ShutdownReport describes the last Stop of a manager.
+	ShutdownReport struct {
+		Began    time.Time     // Time Stop began.
+		Drain    time.Duration // Time spent in the pre-stop drain delay.
+		Duration time.Duration // Time Stop took, including the drain delay.
+		Err      error         // Error returned by Stop, if any.
+	}
+)
+
+// WithDrainDelay makes Stop wait for delay after the manager enters StateStopping and before it stops any service.
+//
+// Readiness, as reported by NewAdminHandler, fails as soon as Stop begins, so load balancers have delay to stop sending
+// traffic while services still serve it. The delay ends early when the context of Stop is done, and takes at most half
+// of the time left until its deadline so services keep time to stop.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(g *Graceful) {
+		g.drainDelay = delay
+	}
+}
+
+// LastShutdown returns the report of the last Stop. The second return value is false if Stop was never called.
+func (g *Graceful) LastShutdown() (ShutdownReport, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.report == nil {
+		return ShutdownReport{}, false
+	}
+
+	return *g.report, true
+}
+
+// drain waits for the drain delay before services are stopped, returning the time it waited.
+func (g *Graceful) drain(ctx context.Context) time.Duration {
+	g.mu.RLock()
+	started := len(g.order) > 0
+	g.mu.RUnlock()
+
+	delay := g.drainDelay
+	if deadline, ok := ctx.Deadline(); ok {
+		delay = min(delay, time.Until(deadline)/2)
+	}
+
+	if !started || delay <= 0 {
+		return 0
+	}
+
+	began := time.Now()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return time.Since(began)
+}
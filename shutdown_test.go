@@ -0,0 +1,74 @@
+package graceful_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestGraceful_DrainDelay(t *testing.T) {
+	t.Run("Waits before stopping services", func(t *testing.T) {
+		var (
+			g       *graceful.Graceful
+			stopped time.Time
+		)
+
+		g = graceful.New(graceful.WithDrainDelay(100*time.Millisecond), graceful.WithObserver(func(ev graceful.Event) {
+			if ev.Service == "db" && ev.State == graceful.StateStopping {
+				stopped = ev.Time
+			}
+		}))
+		g.Add("db", &MockSvc{name: "db"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		_, ok := g.LastShutdown()
+		assert.False(t, ok)
+
+		h := graceful.NewAdminHandler(g)
+		done := make(chan error, 1)
+
+		go func() { done <- g.Stop(ctx) }()
+
+		// Readiness fails during the delay, before any service stops.
+		assert.Eventually(t, func() bool {
+			code, _ := get(t, h, "/readyz")
+			return code == http.StatusServiceUnavailable
+		}, time.Second, 5*time.Millisecond)
+
+		state, _ := g.State("db")
+		assert.Equal(t, graceful.StateRunning, state)
+
+		require.NoError(t, <-done)
+
+		report, ok := g.LastShutdown()
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, report.Drain, 100*time.Millisecond)
+		assert.GreaterOrEqual(t, report.Duration, report.Drain)
+		assert.GreaterOrEqual(t, stopped.Sub(report.Began), 100*time.Millisecond)
+		assert.NoError(t, report.Err)
+	})
+
+	t.Run("Is cut short by the deadline", func(t *testing.T) {
+		g := graceful.New(graceful.WithDrainDelay(time.Minute))
+		g.Add("db", &MockSvc{name: "db"})
+
+		require.NoError(t, g.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, g.Stop(ctx))
+
+		report, _ := g.LastShutdown()
+		assert.Less(t, report.Drain, 150*time.Millisecond)
+		assert.Greater(t, report.Drain, 50*time.Millisecond)
+	})
+}
@@ -0,0 +1,284 @@
+package graceful
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// State is the lifecycle state of a service.
+	State int
+
+	// Event describes a lifecycle transition of a service, or of the manager itself.
+	//
+	// Events of the manager itself have an empty Service and are delivered only to its own observers: StateStarting,
+	// StateStopping and StateReloading when Start, Stop and Reload begin, and StateRunning, StateStopped or StateFailed
+	// when they return.
+	//
+	// A failed reload leaves the service running: its event has StateRunning and a non-nil Err.
+	Event struct {
+		Service  string        // Hierarchical service name, e.g. "billing/db", or empty for the manager itself.
+		State    State         // State the service transitioned to.
+		Err      error         // Error that caused the transition, if any.
+		Time     time.Time     // Time of the transition.
+		Duration time.Duration // Time spent in the previous state, e.g. the start duration for StateRunning.
+	}
+
+	// ServiceStatus is a snapshot of the lifecycle of a service.
+	ServiceStatus struct {
+		Name     string    // Hierarchical service name, e.g. "billing/db".
+		State    State     // Current lifecycle state.
+		Deps     []string  // Hierarchical names of the dependencies.
+		Since    time.Time // Time the service last started, zero if it never did.
+		Restarts int       // Number of times the service was restarted.
+		Err      error     // Last error reported by the service, if any.
+		Probe    *Probe    // Health of the service, nil if it is not probed.
+	}
+
+	// Observer is called for every lifecycle event of a manager and its children.
+	//
+	// Observers are called synchronously from the goroutine performing the transition and must not block.
+	Observer func(Event)
+)
+
+const (
+	StateStopped   State = iota // Service is not running.
+	StateStarting               // Service Start is in progress.
+	StateRunning                // Service started successfully.
+	StateStopping               // Service Stop is in progress.
+	StateFailed                 // Service failed to start or stop.
+	StateReloading              // Service Reload is in progress.
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "stopped"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateFailed:
+		return "failed"
+	case StateReloading:
+		return "reloading"
+	default:
+		return "unknown"
+	}
+}
+
+// WithObserver registers fn to be called for every lifecycle event of the manager and its children.
+func WithObserver(fn Observer) Option {
+	return func(g *Graceful) {
+		g.observers = append(g.observers, fn)
+	}
+}
+
+// State returns the state of the named service.
+//
+// The name may be hierarchical, e.g. "billing/db", in which case the lookup descends into child managers. The second
+// return value is false if no such service is registered.
+func (g *Graceful) State(name string) (State, bool) {
+	m, svc, ok := g.lookup(name)
+	if !ok {
+		return StateStopped, false
+	}
+
+	return m.state(svc.Name), true
+}
+
+// States returns the state of every registered service, including the services of child managers, keyed by
+// hierarchical name relative to g.
+func (g *Graceful) States() map[string]State {
+	states := make(map[string]State, len(g.svcs))
+
+	for name, svc := range g.svcs {
+		states[name] = g.state(name)
+
+		if child, ok := svc.Service.(*Graceful); ok {
+			for sub, state := range child.States() {
+				states[name+"/"+sub] = state
+			}
+		}
+	}
+
+	return states
+}
+
+// Status returns the lifecycle state of the manager itself.
+func (g *Graceful) Status() State {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.status
+}
+
+// Inspect returns a snapshot of every registered service, including the services of child managers, sorted by
+// hierarchical name relative to g.
+func (g *Graceful) Inspect() []ServiceStatus {
+	probes := g.Probes()
+	statuses := make([]ServiceStatus, 0, len(g.svcs))
+
+	g.mu.RLock()
+	for name, svc := range g.svcs {
+		deps := make([]string, len(svc.Deps))
+		copy(deps, svc.Deps)
+
+		status := ServiceStatus{
+			Name:     name,
+			State:    svc.state,
+			Deps:     deps,
+			Since:    svc.since,
+			Restarts: svc.restarts,
+			Err:      svc.err,
+		}
+
+		if probe, ok := probes[name]; ok {
+			status.Probe = &probe
+		}
+
+		statuses = append(statuses, status)
+	}
+	g.mu.RUnlock()
+
+	for name, svc := range g.svcs {
+		child, ok := svc.Service.(*Graceful)
+		if !ok {
+			continue
+		}
+
+		for _, status := range child.Inspect() {
+			status.Name = name + "/" + status.Name
+
+			for i, dep := range status.Deps {
+				status.Deps[i] = name + "/" + dep
+			}
+
+			statuses = append(statuses, status)
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b ServiceStatus) int { return strings.Compare(a.Name, b.Name) })
+
+	return statuses
+}
+
+// lookup resolves a possibly hierarchical service name to the manager owning the service and its definition.
+func (g *Graceful) lookup(name string) (*Graceful, *ServiceDef, bool) {
+	if svc, ok := g.svcs[name]; ok {
+		return g, svc, true
+	}
+
+	head, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return nil, nil, false
+	}
+
+	svc, ok := g.svcs[head]
+	if !ok {
+		return nil, nil, false
+	}
+
+	child, ok := svc.Service.(*Graceful)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return child.lookup(rest)
+}
+
+// state returns the current state of the named service.
+func (g *Graceful) state(name string) State {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if svc, ok := g.svcs[name]; ok {
+		return svc.state
+	}
+
+	return StateStopped
+}
+
+// transition moves svc to state, records err and notifies observers.
+func (g *Graceful) transition(svc *ServiceDef, state State, err error) {
+	now := time.Now()
+
+	g.mu.Lock()
+	var elapsed time.Duration
+	if !svc.changed.IsZero() {
+		elapsed = now.Sub(svc.changed)
+	}
+
+	if state == StateRunning && svc.state == StateStarting {
+		svc.since = now
+		svc.took = elapsed
+	}
+
+	svc.state = state
+	svc.changed = now
+
+	if err != nil {
+		svc.err = err
+	}
+	g.mu.Unlock()
+
+	ev := Event{Service: g.qualify(svc.Name), State: state, Err: err, Time: now, Duration: elapsed}
+
+	g.record(ev)
+	g.emit(ev)
+}
+
+// fail marks svc as failed after it has started, e.g. when a Runner returns unexpectedly, and notifies the run loops
+// of g and its parents.
+func (g *Graceful) fail(svc *ServiceDef, err error) {
+	err = g.wrap(svc, "service failed", err)
+
+	g.transition(svc, StateFailed, err)
+	g.notify(err)
+}
+
+// notify reports a failure to the run loops of g and its parents.
+func (g *Graceful) notify(err error) {
+	for m := g; m != nil; m = m.parent {
+		select {
+		case m.failures <- err:
+		default:
+		}
+	}
+}
+
+// advance moves the manager itself to state and notifies its own observers.
+func (g *Graceful) advance(state State, err error) {
+	now := time.Now()
+
+	g.mu.Lock()
+	var elapsed time.Duration
+	if !g.changed.IsZero() {
+		elapsed = now.Sub(g.changed)
+	}
+
+	g.status = state
+	g.changed = now
+	g.mu.Unlock()
+
+	ev := Event{State: state, Err: err, Time: now, Duration: elapsed}
+
+	g.record(ev)
+
+	for _, fn := range g.observers {
+		fn(ev)
+	}
+}
+
+// emit delivers ev to the observers of g and of all its parents.
+func (g *Graceful) emit(ev Event) {
+	for m := g; m != nil; m = m.parent {
+		for _, fn := range m.observers {
+			fn(ev)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package graceful
+
+import (
+	"bytes"
+	"log/slog"
+	"maps"
+	"os"
+	"runtime/pprof"
+	"slices"
+	"strings"
+)
+
+type (
+	// watchedKey is the context key marking a Stop whose stuck services are reported by an enclosing Stop.
+	watchedKey struct{}
+)
+
+// WithGoroutineDump makes Stop write the goroutine stacks of the process to the file at path, rather than to the
+// logger, when it gets stuck.
+//
+// Stop is stuck when its context is done while services are still stopping. It then logs the hierarchical names of
+// those services, and separately of the services waiting for them to stop, and dumps the stacks of all goroutines, so
+// a stuck shutdown can be diagnosed from a single occurrence, and returns an error naming them without waiting for
+// them any longer. Child managers stopped by their parent are reported by the parent and use its settings.
+func WithGoroutineDump(path string) Option {
+	return func(g *Graceful) {
+		g.dump = path
+	}
+}
+
+// WithShutdownGoroutineDump makes Shutdown write the goroutine stacks of the process to the file at path, rather than
+// to the logger, when its timeout is reached while cleanups are still running.
+func WithShutdownGoroutineDump(path string) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.dump = path
+	}
+}
+
+// stuck returns the hierarchical names of the services still stopping, including the services of child managers, and
+// of the services of g that have not begun to stop as they wait for dependents, given the channels closed once each
+// service of g stopped.
+func (g *Graceful) stuck(done map[string]chan struct{}) (stopping, waiting []string) {
+	for _, status := range g.Inspect() {
+		if status.State == StateStopping {
+			stopping = append(stopping, g.qualify(status.Name))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(done)) {
+		select {
+		case <-done[name]:
+		default:
+			if !slices.Contains(stopping, g.qualify(name)) {
+				waiting = append(waiting, g.qualify(name))
+			}
+		}
+	}
+
+	return stopping, waiting
+}
+
+// describeStuck lists the stuck services for the error returned by Stop and the audit log.
+func describeStuck(stopping, waiting []string) string {
+	parts := make([]string, 0, 2)
+
+	if len(stopping) > 0 {
+		parts = append(parts, "still stopping: "+strings.Join(stopping, ", "))
+	}
+
+	if len(waiting) > 0 {
+		parts = append(parts, "waiting for dependents: "+strings.Join(waiting, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// reportStuck logs the stuck services, records them in the audit log and dumps the stacks of all goroutines.
+func (g *Graceful) reportStuck(stopping, waiting []string, manager string) {
+	logger, _ := g.log()
+	logger.Warn("graceful: shutdown deadline reached, services still stopping", "services", stopping, "waiting", waiting)
+
+	g.journal(auditRecord{Kind: "shutdown", Service: manager, Step: "stuck", Error: describeStuck(stopping, waiting)})
+	g.flush()
+
+	path := ""
+	for m := g; m != nil && path == ""; m = m.parent {
+		path = m.dump
+	}
+
+	dumpGoroutines(logger, path)
+}
+
+// dumpGoroutines writes the stacks of all goroutines to the file at path, or to logger if path is empty or the file
+// cannot be written.
+func dumpGoroutines(logger *slog.Logger, path string) {
+	var b bytes.Buffer
+
+	_ = pprof.Lookup("goroutine").WriteTo(&b, 2)
+
+	if path != "" {
+		err := os.WriteFile(path, b.Bytes(), 0o644)
+		if err == nil {
+			logger.Warn("graceful: goroutine stacks dumped", "path", path)
+			return
+		}
+
+		logger.Error("graceful: goroutine dump not written", "path", path, "error", err)
+	}
+
+	logger.Warn("graceful: goroutine stacks", "goroutines", b.String())
+}
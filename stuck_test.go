@@ -0,0 +1,234 @@
+package graceful_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// stuck returns a service whose Stop ignores its context and blocks until release is closed.
+func stuck(release <-chan struct{}) graceful.Service {
+	return graceful.FromFuncs(nil, func(context.Context) error {
+		<-release
+		return nil
+	})
+}
+
+// count returns the number of records with the given message.
+func count(records []map[string]any, msg string) int {
+	n := 0
+
+	for _, r := range records {
+		if r["msg"] == msg {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestGraceful_StuckStop(t *testing.T) {
+	t.Run("Names stuck services and dumps goroutines to the logger", func(t *testing.T) {
+		logs := &syncBuffer{}
+		release := make(chan struct{})
+
+		child := graceful.New()
+		child.Add("db", stuck(release))
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("billing", child)
+		g.Add("cache", &MockSvc{name: "cache"})
+
+		require.NoError(t, g.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+		began := time.Now()
+		err := g.Stop(ctx)
+
+		assert.Less(t, time.Since(began), 150*time.Millisecond, "Stop returns once its context is done")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "billing/db")
+		assert.Equal(t, 1, strings.Count(err.Error(), "still stopping"), "stuck services are reported once")
+
+		recs := records(t, logs)
+
+		warn := find(recs, "graceful: shutdown deadline reached, services still stopping", "")
+		require.NotNil(t, warn)
+		assert.Contains(t, warn["services"], "billing/db")
+
+		assert.Equal(t, 1, count(recs, "graceful: goroutine stacks"), "child managers are watched by their parent")
+
+		dump := find(recs, "graceful: goroutine stacks", "")
+		require.NotNil(t, dump)
+		assert.Contains(t, dump["goroutines"], "goroutine ")
+	})
+
+	t.Run("Lists dependencies waiting for stuck dependents separately", func(t *testing.T) {
+		logs := &syncBuffer{}
+		release := make(chan struct{})
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("db", &MockSvc{name: "db"})
+		g.Add("api", stuck(release), "db")
+
+		require.NoError(t, g.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+		assert.ErrorContains(t, g.Stop(ctx), "still stopping: api; waiting for dependents: db")
+
+		warn := find(records(t, logs), "graceful: shutdown deadline reached, services still stopping", "")
+		require.NotNil(t, warn)
+		assert.Equal(t, []any{"api"}, warn["services"])
+		assert.Equal(t, []any{"db"}, warn["waiting"])
+	})
+
+	t.Run("Writes goroutines to a file", func(t *testing.T) {
+		logs := &syncBuffer{}
+		path := filepath.Join(t.TempDir(), "goroutines.txt")
+		release := make(chan struct{})
+
+		g := graceful.New(
+			graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))),
+			graceful.WithGoroutineDump(path),
+		)
+		g.Add("db", stuck(release))
+
+		require.NoError(t, g.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+		assert.ErrorContains(t, g.Stop(ctx), "still stopping: db")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "goroutine ")
+
+		dumped := find(records(t, logs), "graceful: goroutine stacks dumped", "")
+		require.NotNil(t, dumped)
+		assert.Equal(t, path, dumped["path"])
+	})
+
+	t.Run("Run returns once its timeout is reached", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		g := graceful.New(graceful.WithLogger(nil))
+		g.Add("db", stuck(release))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+
+		go func() { done <- g.Run(ctx, 50*time.Millisecond) }()
+
+		require.Eventually(t, func() bool {
+			state, _ := g.State("db")
+			return state == graceful.StateRunning
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.ErrorContains(t, err, "still stopping: db")
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return after its shutdown timeout")
+		}
+	})
+
+	t.Run("Stays quiet when services stop in time", func(t *testing.T) {
+		logs := &syncBuffer{}
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("db", &MockSvc{name: "db"})
+
+		require.NoError(t, g.Start(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.NoError(t, g.Stop(ctx))
+		assert.NotContains(t, logs.String(), "goroutine stacks")
+	})
+}
+
+func TestShutdown_Stuck(t *testing.T) {
+	t.Run("Names stuck cleanups and dumps goroutines", func(t *testing.T) {
+		logs := &syncBuffer{}
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		interrupt := make(chan any, 1)
+
+		cleanups := []graceful.Cleanup{
+			func(context.Context) error { return nil },
+			func(context.Context) error {
+				defer close(finished)
+
+				<-release
+
+				return nil
+			},
+		}
+
+		code := graceful.Shutdown(context.Background(), cleanups, interrupt, 50*time.Millisecond, 0,
+			graceful.WithShutdownLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+
+		assert.Equal(t, 1, code)
+
+		// The stuck cleanup finishing after Shutdown returned must not panic.
+		close(release)
+		<-finished
+
+		recs := records(t, logs)
+
+		warn := find(recs, "graceful: shutdown timeout reached, cleanups still running", "")
+		require.NotNil(t, warn)
+		assert.Equal(t, []any{"cleanup/1"}, warn["cleanups"])
+
+		dump := find(recs, "graceful: goroutine stacks", "")
+		require.NotNil(t, dump)
+		assert.Contains(t, dump["goroutines"], "goroutine ")
+
+		failed := find(recs, "graceful: manager failed", "")
+		require.NotNil(t, failed)
+		assert.Contains(t, failed["error"], "still running: cleanup/1")
+	})
+
+	t.Run("Writes goroutines to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "goroutines.txt")
+		release := make(chan struct{})
+		interrupt := make(chan any, 1)
+
+		defer close(release)
+
+		cleanups := []graceful.Cleanup{func(context.Context) error { <-release; return nil }}
+
+		code := graceful.Shutdown(context.Background(), cleanups, interrupt, 50*time.Millisecond, 0,
+			graceful.WithShutdownLogger(nil), graceful.WithShutdownGoroutineDump(path))
+
+		assert.Equal(t, 1, code)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "goroutine ")
+	})
+}
@@ -0,0 +1,200 @@
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Systemd notifies the service manager of lifecycle changes using the [sd_notify] protocol.
+	//
+	// It is configured from the environment: NOTIFY_SOCKET names the socket notifications are sent to, and WATCHDOG_USEC
+	// and WATCHDOG_PID enable watchdog pings. If NOTIFY_SOCKET is not set, all notifications are silently dropped.
+	//
+	// [sd_notify]: https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html
+	Systemd struct {
+		addr     *net.UnixAddr // Notification socket, nil if not running under systemd.
+		watchdog time.Duration // Watchdog timeout, zero if disabled.
+		mu       sync.Mutex    // Guards the fields below.
+		conn     *net.UnixConn // Connection to the notification socket.
+		stop     chan struct{} // Closed to stop watchdog pings.
+	}
+)
+
+// NewSystemd creates a Systemd notifier configured from the environment.
+func NewSystemd() *Systemd {
+	s := &Systemd{}
+
+	if socket := os.Getenv("NOTIFY_SOCKET"); socket != "" {
+		s.addr = &net.UnixAddr{Name: socket, Net: "unixgram"}
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return s
+	}
+
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+		s.watchdog = time.Duration(usec) * time.Microsecond
+	}
+
+	return s
+}
+
+// WithSystemd notifies systemd of the lifecycle of the manager through s.
+//
+// READY=1 is sent once Start succeeds and STOPPING=1 when Stop begins, with STATUS= lines reporting progress in
+// between. RELOADING=1 is sent when Reload begins, followed by READY=1 when it completes. While the manager is running
+// and no service has failed or is unhealthy, WATCHDOG=1 is sent at half the watchdog timeout.
+func WithSystemd(s *Systemd) Option {
+	return func(g *Graceful) {
+		g.observers = append(g.observers, func(ev Event) { s.observe(g, ev) })
+	}
+}
+
+// Enabled reports whether the process runs under a systemd service expecting notifications.
+func (s *Systemd) Enabled() bool {
+	return s.addr != nil
+}
+
+// Watchdog returns the watchdog timeout, or zero if the watchdog is disabled.
+func (s *Systemd) Watchdog() time.Duration {
+	return s.watchdog
+}
+
+// Notify sends the given state assignments, e.g. "READY=1", in a single notification.
+func (s *Systemd) Notify(states ...string) error {
+	if s.addr == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil, s.addr)
+		if err != nil {
+			return err
+		}
+
+		s.conn = conn
+	}
+
+	_, err := s.conn.Write([]byte(strings.Join(states, "\n")))
+
+	return err
+}
+
+// observe translates lifecycle events of g into notifications.
+func (s *Systemd) observe(g *Graceful, ev Event) {
+	if ev.Service != "" {
+		switch ev.State {
+		case StateStarting, StateStopping:
+			_ = s.Notify("STATUS=" + progress(g, ev))
+		case StateFailed:
+			_ = s.Notify(fmt.Sprintf("STATUS=%s failed: %v", ev.Service, ev.Err))
+		}
+
+		return
+	}
+
+	switch ev.State {
+	case StateStarting:
+		_ = s.Notify("STATUS=starting")
+	case StateRunning:
+		_ = s.Notify("READY=1", "STATUS="+progress(g, ev))
+		s.ping(g)
+	case StateStopping:
+		s.halt()
+		_ = s.Notify("STOPPING=1", "STATUS=stopping")
+	case StateStopped:
+		_ = s.Notify("STATUS=stopped")
+	case StateFailed:
+		s.halt()
+		_ = s.Notify(fmt.Sprintf("STATUS=failed: %v", ev.Err))
+	case StateReloading:
+		_ = s.Notify("RELOADING=1", "STATUS=reloading")
+	}
+}
+
+// ping sends watchdog pings at half the watchdog timeout while all services of g are healthy, until halt is called.
+func (s *Systemd) ping(g *Graceful) {
+	if s.addr == nil || s.watchdog <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(s.watchdog / 2)
+		defer ticker.Stop()
+
+		for {
+			if healthy(g) {
+				_ = s.Notify("WATCHDOG=1")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// halt stops watchdog pings.
+func (s *Systemd) halt() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// progress describes the progress of g for a STATUS= notification.
+func progress(g *Graceful, ev Event) string {
+	states := g.States()
+	running := 0
+
+	for _, state := range states {
+		if state == StateRunning {
+			running++
+		}
+	}
+
+	if ev.Service == "" {
+		return fmt.Sprintf("running %d/%d services", running, len(states))
+	}
+
+	return fmt.Sprintf("%s %s (%d/%d running)", ev.State, ev.Service, running, len(states))
+}
+
+// healthy reports whether no service of g, including the services of child managers, has failed or is unhealthy.
+func healthy(g *Graceful) bool {
+	if g.Health() == HealthUnhealthy {
+		return false
+	}
+
+	for _, state := range g.States() {
+		if state == StateFailed {
+			return false
+		}
+	}
+
+	return true
+}
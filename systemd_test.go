@@ -0,0 +1,143 @@
+package graceful_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// notifications listens on a unixgram socket set as NOTIFY_SOCKET and returns a channel receiving each notification.
+func notifications(t *testing.T) <-chan string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	ch := make(chan string, 100)
+
+	go func() {
+		buf := make([]byte, 4096)
+
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				close(ch)
+				return
+			}
+
+			ch <- string(buf[:n])
+		}
+	}()
+
+	return ch
+}
+
+// await reads notifications until one contains state, failing the test on timeout.
+func await(t *testing.T, ch <-chan string, state string) {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+
+	for {
+		select {
+		case msg := <-ch:
+			if strings.Contains(msg, state) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for %s", state)
+		}
+	}
+}
+
+func TestSystemd(t *testing.T) {
+	t.Run("Disabled without NOTIFY_SOCKET", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+
+		sd := graceful.NewSystemd()
+		assert.False(t, sd.Enabled())
+		assert.NoError(t, sd.Notify("READY=1"))
+	})
+
+	t.Run("Notifies readiness, watchdog and stopping", func(t *testing.T) {
+		ch := notifications(t)
+		t.Setenv("WATCHDOG_USEC", "100000")
+
+		sd := graceful.NewSystemd()
+		assert.True(t, sd.Enabled())
+		assert.Equal(t, 100*time.Millisecond, sd.Watchdog())
+
+		g := graceful.New(graceful.WithSystemd(sd))
+		g.Add("db", &MockSvc{name: "db"})
+		g.Add("api", &MockSvc{name: "api"}, "db")
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		await(t, ch, "READY=1")
+		await(t, ch, "WATCHDOG=1")
+		await(t, ch, "WATCHDOG=1")
+
+		require.NoError(t, g.Stop(ctx))
+
+		await(t, ch, "STOPPING=1")
+		await(t, ch, "STATUS=stopped")
+	})
+
+	t.Run("Notifies reloading", func(t *testing.T) {
+		ch := notifications(t)
+		t.Setenv("WATCHDOG_USEC", "")
+
+		g := graceful.New(graceful.WithSystemd(graceful.NewSystemd()))
+		g.Add("db", &MockSvc{name: "db"})
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		await(t, ch, "READY=1")
+
+		require.NoError(t, g.Reload(ctx))
+		await(t, ch, "RELOADING=1")
+		await(t, ch, "READY=1")
+
+		require.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Watchdog stops when a service fails", func(t *testing.T) {
+		ch := notifications(t)
+		t.Setenv("WATCHDOG_USEC", "50000")
+
+		g := graceful.New(graceful.WithSystemd(graceful.NewSystemd()))
+		g.Add("worker", graceful.NewRunner(&Worker{delay: 50 * time.Millisecond, exit: assert.AnError}))
+
+		require.NoError(t, g.Start(context.Background()))
+
+		await(t, ch, "READY=1")
+		await(t, ch, "STATUS=worker failed")
+
+		// Drain pings sent before the failure was observed.
+		time.Sleep(50 * time.Millisecond)
+
+		for len(ch) > 0 {
+			<-ch
+		}
+
+		select {
+		case msg := <-ch:
+			assert.NotContains(t, msg, "WATCHDOG=1")
+		case <-time.After(150 * time.Millisecond):
+		}
+	})
+}
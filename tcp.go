@@ -0,0 +1,253 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// ConnHandler handles a connection accepted by a TCPServer. ctx is cancelled when the server begins to stop, and the
+	// handler should finish its current work and return.
+	ConnHandler func(ctx context.Context, conn net.Conn)
+
+	// TCPServer is a Service accepting stream connections on a "tcp" or "unix" listener and handling each in its own
+	// goroutine.
+	//
+	// The listener is bound during Start. Stop stops accepting, waits for active connections to finish until the stop
+	// context is done, and then force-closes the rest. Unix socket files are removed once the listener is closed.
+	TCPServer struct {
+		network  string
+		address  string
+		handler  ConnHandler
+		bind     binding               // Source of the listener.
+		handlers sync.WaitGroup        // Tracks running handlers.
+		mu       sync.Mutex            // Guards the fields below.
+		ln       net.Listener          // Listener bound by Start.
+		conns    map[net.Conn]struct{} // Active connections.
+		cancel   context.CancelFunc    // Cancels the context passed to handlers.
+		done     chan struct{}         // Closed when the accept loop returns.
+		stopping bool                  // Whether Stop has been called.
+		owned    bool                  // Whether the listener was bound rather than inherited.
+		fail     func(error)           // Reports a failure after Start, set by the manager.
+		name     string                // Hierarchical name of the service, set by the manager.
+		logger   *slog.Logger          // Logs panics that cannot be reported as failures, set by the manager.
+	}
+)
+
+// NewTCPServer creates a Service accepting connections on the given network ("tcp", "tcp4", "tcp6" or "unix") and
+// address, calling handler for each connection. The listener is taken from a Listeners registry if configured with
+// Listeners.Use.
+func NewTCPServer(network, address string, handler ConnHandler, opts ...ServerOption) *TCPServer {
+	s := &TCPServer{network: network, address: address, handler: handler}
+
+	for _, opt := range opts {
+		opt(&s.bind)
+	}
+
+	return s
+}
+
+// Addr returns the address the server is listening on, or nil if it is not started.
+func (s *TCPServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return nil
+	}
+
+	return s.ln.Addr()
+}
+
+// ActiveConns returns the number of connections currently being handled.
+func (s *TCPServer) ActiveConns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+func (s *TCPServer) supervise(name string, logger *slog.Logger, fail func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name, s.logger, s.fail = name, logger, fail
+}
+
+// Start binds the listener and accepts connections in the background.
+func (s *TCPServer) Start(ctx context.Context) error {
+	ln, inherited, err := s.bind.listen(ctx, s.network, s.address)
+	if err != nil {
+		return err
+	}
+
+	base, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	s.mu.Lock()
+	s.ln, s.cancel, s.done = ln, cancel, done
+	s.conns = make(map[net.Conn]struct{})
+	s.stopping = false
+	s.owned = !inherited
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		if err := s.accept(base, ln); err != nil {
+			s.mu.Lock()
+			fail := s.fail
+			s.mu.Unlock()
+
+			if fail != nil {
+				fail(err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops accepting connections and waits for active connections to finish. If ctx is done first, the remaining
+// connections are closed and their number is reported in the error.
+func (s *TCPServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+
+	if s.ln == nil || s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.stopping = true
+	ln, done := s.ln, s.done
+	s.cancel()
+	s.mu.Unlock()
+
+	err := ln.Close()
+
+	<-done
+
+	s.unlink()
+
+	finished := make(chan struct{})
+
+	go func() {
+		s.handlers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	remaining := len(s.conns)
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	return errors.Join(
+		fmt.Errorf("graceful: %s server stopped with %d connections open: %w", s.network, remaining, ctx.Err()),
+		err,
+	)
+}
+
+// accept accepts connections until the listener is closed, retrying temporary errors with a backoff.
+func (s *TCPServer) accept(ctx context.Context, ln net.Listener) error {
+	var delay time.Duration
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			stopping := s.stopping
+			s.mu.Unlock()
+
+			if stopping {
+				return nil
+			}
+
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+				time.Sleep(delay)
+
+				continue
+			}
+
+			return err
+		}
+
+		delay = 0
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		name := s.name
+		s.mu.Unlock()
+
+		s.handlers.Add(1)
+
+		go func() {
+			defer s.handlers.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+
+				_ = conn.Close()
+			}()
+
+			err := guard(name, "connection handler panicked", func() error {
+				s.handler(ctx, conn)
+				return nil
+			})
+			if err != nil {
+				s.panicked(err)
+			}
+		}()
+	}
+}
+
+// panicked reports a panic of a connection handler as a failure of the server, or logs it to the logger of the manager
+// if the server is stopping, and to slog.Default if it is not run by a manager.
+func (s *TCPServer) panicked(err error) {
+	s.mu.Lock()
+	fail, logger := s.fail, s.logger
+	if s.stopping {
+		fail = nil
+	}
+	s.mu.Unlock()
+
+	if fail != nil {
+		fail(err)
+		return
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	perr, _ := Panicked(err)
+	logger.Error("graceful: connection handler panicked", "error", err, "stack", string(perr.Stack))
+}
+
+// unlink removes the socket file of a unix listener bound by the server. Inherited sockets belong to the process that
+// passed them, handed off sockets to the process they were passed to, and abstract sockets have no file.
+func (s *TCPServer) unlink() {
+	if !s.owned || s.bind.released() || s.network != "unix" || s.address == "" || strings.HasPrefix(s.address, "@") {
+		return
+	}
+
+	_ = os.Remove(s.address)
+}
@@ -0,0 +1,151 @@
+package graceful_test
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// echo writes back every line it reads until the connection is closed.
+func echo(ctx context.Context, conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		_, _ = conn.Write(append(scanner.Bytes(), '\n'))
+	}
+}
+
+func TestTCPServer(t *testing.T) {
+	t.Run("Waits for connections to finish", func(t *testing.T) {
+		srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", func(ctx context.Context, conn net.Conn) {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+		})
+
+		ctx := context.Background()
+		require.NoError(t, srv.Start(ctx))
+
+		conn, err := net.Dial("tcp", srv.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.Eventually(t, func() bool { return srv.ActiveConns() == 1 }, time.Second, 5*time.Millisecond)
+		assert.NoError(t, srv.Stop(ctx))
+		assert.Equal(t, 0, srv.ActiveConns())
+
+		_, err = net.Dial("tcp", srv.Addr().String())
+		assert.Error(t, err)
+	})
+
+	t.Run("Force-closes connections at deadline", func(t *testing.T) {
+		srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", echo)
+
+		require.NoError(t, srv.Start(context.Background()))
+
+		conn, err := net.Dial("tcp", srv.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("ping\n"))
+		require.NoError(t, err)
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "ping\n", line)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err = srv.Stop(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "1 connections open")
+
+		_, err = bufio.NewReader(conn).ReadString('\n')
+		assert.Error(t, err)
+	})
+
+	t.Run("Panicking handler fails the server", func(t *testing.T) {
+		srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", func(ctx context.Context, conn net.Conn) {
+			panic("bad frame")
+		})
+
+		g := graceful.New(graceful.WithLogger(nil))
+		g.Add("proxy", srv)
+
+		done := make(chan error, 1)
+
+		go func() { done <- g.Run(context.Background(), time.Second) }()
+
+		require.Eventually(t, func() bool { return srv.Addr() != nil }, time.Second, 5*time.Millisecond)
+
+		conn, err := net.Dial("tcp", srv.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		select {
+		case err := <-done:
+			perr, ok := graceful.Panicked(err)
+			require.True(t, ok)
+			assert.Equal(t, "proxy", perr.Service)
+			assert.Equal(t, "bad frame", perr.Panic)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Run did not shut down after the handler panicked")
+		}
+	})
+
+	t.Run("Panic while stopping is logged to the logger of the manager", func(t *testing.T) {
+		logs := &syncBuffer{}
+		accepted := make(chan struct{})
+
+		srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", func(ctx context.Context, conn net.Conn) {
+			close(accepted)
+			<-ctx.Done()
+			panic("late frame")
+		})
+
+		g := graceful.New(graceful.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+		g.Add("proxy", srv)
+
+		require.NoError(t, g.Start(context.Background()))
+
+		conn, err := net.Dial("tcp", srv.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		<-accepted
+
+		require.NoError(t, g.Stop(context.Background()))
+
+		panicked := find(records(t, logs), "graceful: connection handler panicked", "")
+		require.NotNil(t, panicked)
+		assert.Contains(t, panicked["error"], "late frame")
+	})
+
+	t.Run("Removes unix socket file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "echo.sock")
+		srv := graceful.NewTCPServer("unix", path, echo)
+
+		g := graceful.New()
+		g.Add("echo", srv)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		_, err := os.Stat(path)
+		assert.NoError(t, err)
+
+		assert.NoError(t, g.Stop(ctx))
+
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
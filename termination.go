@@ -0,0 +1,131 @@
+package graceful
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultTerminationLog is the path Kubernetes reads the termination message of a container from by default.
+	DefaultTerminationLog = "/dev/termination-log"
+
+	// terminationLimit is the size of termination messages the kubelet keeps.
+	terminationLimit = 4096
+
+	// truncated marks a termination message cut to terminationLimit.
+	truncated = "\n[truncated]\n"
+)
+
+// WithTerminationLog makes Run write a termination message to path before it returns, DefaultTerminationLog if path
+// is empty, so that kubectl describe pod explains why the container stopped. See WriteTerminationLog.
+func WithTerminationLog(path string) Option {
+	if path == "" {
+		path = DefaultTerminationLog
+	}
+
+	return func(g *Graceful) {
+		g.termination = path
+	}
+}
+
+// WriteTerminationLog writes a termination message to path: the cause of the shutdown, the service that failed, if
+// any, the exit code and the chain of errors that led to it. The message is truncated to the 4096 bytes the kubelet
+// keeps.
+//
+// Run calls it with the exit code 1 if it returns an error, and 0 otherwise; programs using Shutdown can call it with
+// the code Shutdown returns.
+func WriteTerminationLog(path, cause string, err error, code int) error {
+	return os.WriteFile(path, []byte(termination(cause, err, code)), 0o644)
+}
+
+// terminate writes the termination message of a run, if enabled.
+func (g *Graceful) terminate(cause string, err error) {
+	if g.termination == "" {
+		return
+	}
+
+	code := 0
+	if err != nil {
+		code = 1
+	}
+
+	if werr := WriteTerminationLog(g.termination, cause, err, code); werr != nil {
+		logger, _ := g.log()
+		logger.Warn("graceful: termination log not written", "path", g.termination, "error", werr)
+	}
+}
+
+// termination formats a termination message.
+func termination(cause string, err error, code int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "cause: %s\n", cause)
+
+	var gerr *GracefulError
+	if errors.As(err, &gerr) {
+		fmt.Fprintf(&b, "service: %s\n", gerr.Service)
+	}
+
+	fmt.Fprintf(&b, "exit code: %d\n", code)
+
+	if err != nil {
+		b.WriteString("errors:\n")
+		chain(&b, err, 1)
+	}
+
+	if perr, ok := Panicked(err); ok {
+		fmt.Fprintf(&b, "stack:\n%s", perr.Stack)
+	}
+
+	msg := b.String()
+	if len(msg) <= terminationLimit {
+		return msg
+	}
+
+	// Cut at a rune boundary so the message stays valid UTF-8.
+	cut := terminationLimit - len(truncated)
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+
+	return msg[:cut] + truncated
+}
+
+// chain writes err and the errors it wraps, one per line, indented by depth. Wrapped errors whose message is already
+// part of the message written are skipped, keeping the message short.
+func chain(b *strings.Builder, err error, depth int) {
+	indent := strings.Repeat("  ", depth)
+	msg := err.Error()
+
+	fmt.Fprintf(b, "%s%s\n", indent, strings.ReplaceAll(msg, "\n", "\n"+indent))
+
+	descend(b, err, msg, depth)
+}
+
+// descend writes the errors wrapped by err that are not part of shown, see chain.
+func descend(b *strings.Builder, err error, shown string, depth int) {
+	var children []error
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		children = e.Unwrap()
+	case interface{ Unwrap() error }:
+		children = []error{e.Unwrap()}
+	}
+
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+
+		if strings.Contains(shown, child.Error()) {
+			descend(b, child, shown, depth)
+			continue
+		}
+
+		chain(b, child, depth+1)
+	}
+}
@@ -0,0 +1,106 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestWriteTerminationLog(t *testing.T) {
+	t.Run("Summarises the failure", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+		refused := errors.New("connection refused")
+		err := graceful.NewGracefulError("billing/db", "failed to start", fmt.Errorf("dial db:5432: %w", refused))
+
+		require.NoError(t, graceful.WriteTerminationLog(path, "start failed", err, 1))
+
+		data, rerr := os.ReadFile(path)
+		require.NoError(t, rerr)
+
+		msg := string(data)
+		assert.True(t, strings.HasPrefix(msg, "cause: start failed\nservice: billing/db\nexit code: 1\nerrors:\n"))
+		assert.Contains(t, msg, "dial db:5432: connection refused")
+		assert.Equal(t, 1, strings.Count(msg, "connection refused"), "wrapped errors already shown are skipped")
+	})
+
+	t.Run("Lists joined errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+		err := errors.Join(errors.New("api: timeout"), errors.New("db: closed"))
+
+		require.NoError(t, graceful.WriteTerminationLog(path, "received signal terminated", err, 1))
+
+		data, rerr := os.ReadFile(path)
+		require.NoError(t, rerr)
+
+		want := "cause: received signal terminated\nexit code: 1\nerrors:\n  api: timeout\n  db: closed\n"
+		assert.Equal(t, want, string(data))
+	})
+
+	t.Run("Truncates to the kubelet limit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+		err := errors.New(strings.Repeat("é", 4096))
+
+		require.NoError(t, graceful.WriteTerminationLog(path, "service failed", err, 1))
+
+		data, rerr := os.ReadFile(path)
+		require.NoError(t, rerr)
+
+		assert.LessOrEqual(t, len(data), 4096)
+		assert.True(t, utf8.Valid(data))
+		assert.True(t, strings.HasSuffix(string(data), "[truncated]\n"))
+	})
+}
+
+func TestWithTerminationLog(t *testing.T) {
+	t.Run("Run writes the cause of a clean shutdown", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+
+		g := graceful.New(graceful.WithTerminationLog(path))
+		g.Add("db", &MockSvc{name: "db"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+
+		go func() { done <- g.Run(ctx, time.Second) }()
+
+		require.Eventually(t, func() bool {
+			state, _ := g.State("db")
+			return state == graceful.StateRunning
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, "cause: context canceled\nexit code: 0\n", string(data))
+	})
+
+	t.Run("Run writes the service that failed to start", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+
+		g := graceful.New(graceful.WithTerminationLog(path))
+		g.Add("db", &FailingSvc{err: errors.New("connection refused")})
+
+		require.Error(t, g.Run(context.Background(), time.Second))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		msg := string(data)
+		assert.Contains(t, msg, "cause: start failed\nservice: db\nexit code: 1\n")
+		assert.Contains(t, msg, "connection refused")
+	})
+}
@@ -0,0 +1,264 @@
+package graceful
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Timeline records when each service starts, runs, reloads and stops, and exports the intervals in the Chrome
+	// trace event format, viewable in Perfetto (https://ui.perfetto.dev) or chrome://tracing.
+	//
+	// Every service gets a track of its own, with "start", "running", "reload" and "stop" slices. Flow arrows connect
+	// the moment a dependency became ready to the start of each service depending on it, so the chain that held up a
+	// slow boot can be followed visually.
+	Timeline struct {
+		mu        sync.Mutex            // Guards the fields below.
+		g         *Graceful             // Manager the intervals are recorded from.
+		origin    time.Time             // Time of the first recorded event.
+		intervals map[string][]interval // Intervals by hierarchical service name.
+		order     []string              // Services in the order they were first seen.
+	}
+
+	// interval is a slice of the track of a service.
+	interval struct {
+		name  string    // "start", "running", "reload" or "stop".
+		begin time.Time // Time the interval began.
+		end   time.Time // Time the interval ended, zero while it is open.
+		err   error     // Error the interval ended with, if any.
+	}
+
+	// traceEvent is an event of the Chrome trace event format.
+	traceEvent struct {
+		Name     string         `json:"name"`
+		Category string         `json:"cat,omitempty"`
+		Phase    string         `json:"ph"`
+		Time     float64        `json:"ts"`
+		Duration *float64       `json:"dur,omitempty"`
+		Pid      int            `json:"pid"`
+		Tid      int            `json:"tid"`
+		ID       int            `json:"id,omitempty"`
+		Binding  string         `json:"bp,omitempty"`
+		Args     map[string]any `json:"args,omitempty"`
+	}
+)
+
+// NewTimeline creates an empty Timeline.
+func NewTimeline() *Timeline {
+	return &Timeline{intervals: make(map[string][]interval)}
+}
+
+// WithTimeline makes the manager record the lifecycle of its services, and those of its child managers, in t.
+func WithTimeline(t *Timeline) Option {
+	return func(g *Graceful) {
+		t.mu.Lock()
+		t.g = g
+		t.mu.Unlock()
+
+		g.observers = append(g.observers, t.observe)
+	}
+}
+
+// observe records a lifecycle event.
+func (t *Timeline) observe(ev Event) {
+	if ev.Service == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Dependencies are looked up by the names Inspect reports, relative to the manager.
+	if t.g != nil {
+		ev.Service = t.g.relative(ev.Service)
+	}
+
+	if t.origin.IsZero() {
+		t.origin = ev.Time
+	}
+
+	if _, ok := t.intervals[ev.Service]; !ok {
+		t.order = append(t.order, ev.Service)
+	}
+
+	switch ev.State {
+	case StateStarting:
+		t.open(ev, "start")
+	case StateRunning:
+		t.close(ev, "start", "reload")
+
+		if !t.isOpen(ev.Service, "running") {
+			t.open(ev, "running")
+		}
+	case StateReloading:
+		t.open(ev, "reload")
+	case StateStopping:
+		t.close(ev, "running")
+		t.open(ev, "stop")
+	case StateStopped, StateFailed:
+		t.close(ev, "start", "running", "reload", "stop")
+	}
+}
+
+// open begins an interval of the service of ev. The caller must hold t.mu.
+func (t *Timeline) open(ev Event, name string) {
+	t.intervals[ev.Service] = append(t.intervals[ev.Service], interval{name: name, begin: ev.Time})
+}
+
+// isOpen reports whether the service has an open interval with the given name. The caller must hold t.mu.
+func (t *Timeline) isOpen(service, name string) bool {
+	for _, iv := range t.intervals[service] {
+		if iv.name == name && iv.end.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// close ends the open intervals of the service of ev with the given names. The caller must hold t.mu.
+func (t *Timeline) close(ev Event, names ...string) {
+	intervals := t.intervals[ev.Service]
+
+	for i := range intervals {
+		if intervals[i].end.IsZero() && slices.Contains(names, intervals[i].name) {
+			intervals[i].end = ev.Time
+			intervals[i].err = ev.Err
+		}
+	}
+}
+
+// WriteTo writes the timeline as a Chrome trace event JSON object. Intervals still open end at the time of the call.
+func (t *Timeline) WriteTo(w io.Writer) (int64, error) {
+	t.mu.Lock()
+	g := t.g
+	t.mu.Unlock()
+
+	deps := make(map[string][]string)
+
+	if g != nil {
+		for _, status := range g.Inspect() {
+			deps[status.Name] = status.Deps
+		}
+	}
+
+	t.mu.Lock()
+	events := t.events(deps, time.Now())
+	t.mu.Unlock()
+
+	data, err := json.Marshal(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{events, "ms"})
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+
+	return int64(n), err
+}
+
+// events converts the intervals to trace events. The caller must hold t.mu.
+func (t *Timeline) events(deps map[string][]string, now time.Time) []traceEvent {
+	micros := func(at time.Time) float64 { return float64(at.Sub(t.origin).Nanoseconds()) / 1e3 }
+	tids := make(map[string]int, len(t.order))
+	events := make([]traceEvent, 0)
+
+	for i, service := range t.order {
+		tid := i + 1
+		tids[service] = tid
+
+		events = append(events,
+			traceEvent{Name: "thread_name", Phase: "M", Pid: 1, Tid: tid, Args: map[string]any{"name": service}},
+			traceEvent{Name: "thread_sort_index", Phase: "M", Pid: 1, Tid: tid, Args: map[string]any{"sort_index": tid}},
+		)
+
+		for _, iv := range t.intervals[service] {
+			end := iv.end
+			if end.IsZero() {
+				end = now
+			}
+
+			dur := micros(end) - micros(iv.begin)
+			ev := traceEvent{
+				Name:     iv.name,
+				Category: "graceful",
+				Phase:    "X",
+				Time:     micros(iv.begin),
+				Duration: &dur,
+				Pid:      1,
+				Tid:      tid,
+			}
+
+			if iv.err != nil {
+				ev.Args = map[string]any{"error": iv.err.Error()}
+			}
+
+			events = append(events, ev)
+		}
+	}
+
+	// Flow arrows run from the moment a dependency became ready to each start of a service depending on it.
+	id := 0
+
+	for _, service := range t.order {
+		for _, start := range t.intervals[service] {
+			if start.name != "start" {
+				continue
+			}
+
+			for _, dep := range deps[service] {
+				ready, ok := t.ready(dep, start.begin)
+				if !ok {
+					continue
+				}
+
+				id++
+
+				events = append(events,
+					traceEvent{
+						Name:     "dependency",
+						Category: "graceful",
+						Phase:    "s",
+						Time:     micros(ready),
+						Pid:      1,
+						Tid:      tids[dep],
+						ID:       id,
+					},
+					traceEvent{
+						Name:     "dependency",
+						Category: "graceful",
+						Phase:    "f",
+						Binding:  "e",
+						Time:     micros(start.begin),
+						Pid:      1,
+						Tid:      tids[service],
+						ID:       id,
+					},
+				)
+			}
+		}
+	}
+
+	return events
+}
+
+// ready returns the last time the service became ready no later than before. The caller must hold t.mu.
+func (t *Timeline) ready(service string, before time.Time) (time.Time, bool) {
+	var (
+		at    time.Time
+		found bool
+	)
+
+	for _, iv := range t.intervals[service] {
+		if iv.name == "running" && !iv.begin.After(before) {
+			at, found = iv.begin, true
+		}
+	}
+
+	return at, found
+}
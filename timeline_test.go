@@ -0,0 +1,120 @@
+package graceful_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// chromeTrace is a decoded Chrome trace event JSON object.
+type chromeTrace struct {
+	TraceEvents []struct {
+		Name  string         `json:"name"`
+		Phase string         `json:"ph"`
+		Time  float64        `json:"ts"`
+		Dur   float64        `json:"dur"`
+		Tid   int            `json:"tid"`
+		ID    int            `json:"id"`
+		Args  map[string]any `json:"args"`
+	} `json:"traceEvents"`
+}
+
+// export writes timeline and decodes it.
+func export(t *testing.T, timeline *graceful.Timeline) chromeTrace {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	_, err := timeline.WriteTo(&buf)
+	require.NoError(t, err)
+
+	var trace chromeTrace
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+
+	return trace
+}
+
+func TestTimeline(t *testing.T) {
+	t.Run("Exports tracks and dependency flows", func(t *testing.T) {
+		timeline := graceful.NewTimeline()
+
+		g := graceful.New(graceful.WithTimeline(timeline))
+		g.Add("db", graceful.FromFuncs(func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}, nil))
+		g.Add("api", &MockSvc{name: "api"}, "db")
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		trace := export(t, timeline)
+
+		tracks := make(map[string]int)
+		slices := make(map[int][]string)
+		flows := make(map[string]int)
+
+		for _, ev := range trace.TraceEvents {
+			switch ev.Phase {
+			case "M":
+				if ev.Name == "thread_name" {
+					tracks[ev.Args["name"].(string)] = ev.Tid
+				}
+			case "X":
+				slices[ev.Tid] = append(slices[ev.Tid], ev.Name)
+
+				if ev.Tid == tracks["db"] && ev.Name == "start" {
+					assert.GreaterOrEqual(t, ev.Dur, 10e3)
+				}
+			case "s", "f":
+				flows[ev.Phase] = ev.Tid
+			}
+		}
+
+		require.Len(t, tracks, 2)
+		assert.Equal(t, []string{"start", "running", "stop"}, slices[tracks["db"]])
+		assert.Equal(t, []string{"start", "running", "stop"}, slices[tracks["api"]])
+
+		// The arrow leaves db when it became ready and enters api when it started.
+		assert.Equal(t, map[string]int{"s": tracks["db"], "f": tracks["api"]}, flows)
+	})
+	t.Run("Draws dependency flows of a child manager", func(t *testing.T) {
+		timeline := graceful.NewTimeline()
+
+		child := graceful.New(graceful.WithTimeline(timeline))
+		child.Add("db", &MockSvc{name: "db"})
+		child.Add("api", &MockSvc{name: "api"}, "db")
+
+		g := graceful.New()
+		g.Add("billing", child)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		tracks := make(map[string]int)
+		flows := make(map[string]int)
+
+		for _, ev := range export(t, timeline).TraceEvents {
+			switch ev.Phase {
+			case "M":
+				if ev.Name == "thread_name" {
+					tracks[ev.Args["name"].(string)] = ev.Tid
+				}
+			case "s", "f":
+				flows[ev.Phase] = ev.Tid
+			}
+		}
+
+		assert.Contains(t, tracks, "db")
+		assert.Equal(t, map[string]int{"s": tracks["db"], "f": tracks["api"]}, flows)
+	})
+}
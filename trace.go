@@ -0,0 +1,136 @@
+package graceful
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Tracer starts spans around lifecycle operations. It is small enough to bridge to OpenTelemetry or any other
+	// tracing library in a few lines.
+	//
+	// The manager starts a span for each phase, named graceful.start, graceful.stop, graceful.reload or
+	// graceful.restart, and a child span for each service it starts, stops, reloads or probes, named
+	// graceful.service.start, graceful.service.stop, graceful.service.reload or graceful.service.health. Service spans
+	// and graceful.restart carry the hierarchical service name in the "service" attribute. Health probes run outside
+	// of any phase.
+	Tracer interface {
+		// Start starts a span as a child of the span in ctx, if any, and returns a context carrying the new span.
+		Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+	}
+
+	// Span is an operation started by a Tracer.
+	Span interface {
+		// End ends the span, recording err as its outcome if it is not nil.
+		End(err error)
+	}
+
+	// Recorder is a Tracer keeping ended spans in memory, for tests.
+	Recorder struct {
+		mu    sync.Mutex
+		next  int            // ID of the next span.
+		spans []RecordedSpan // Ended spans, in the order they ended.
+	}
+
+	// RecordedSpan is a span ended by a Recorder.
+	RecordedSpan struct {
+		ID     int         // Identifies the span within its Recorder, starting at 1.
+		Parent int         // ID of the parent span, 0 for root spans.
+		Name   string      // Name of the span.
+		Attrs  []slog.Attr // Attributes given when the span started.
+		Start  time.Time   // Time the span started.
+		End    time.Time   // Time the span ended.
+		Err    error       // Error the span ended with, if any.
+	}
+
+	// recording is a span of a Recorder in progress.
+	recording struct {
+		r    *Recorder
+		span RecordedSpan
+	}
+
+	// nop is the span used when no tracer is configured.
+	nop struct{}
+
+	// spanKey is the context key of the recording in progress.
+	spanKey struct{}
+)
+
+// WithTracer makes the manager, and child managers without a tracer of their own, trace lifecycle operations with t.
+func WithTracer(t Tracer) Option {
+	return func(g *Graceful) {
+		g.tracer = t
+	}
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start starts a span as a child of the Recorder span in ctx, if any.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	r.mu.Lock()
+	r.next++
+	id := r.next
+	r.mu.Unlock()
+
+	rec := &recording{r: r, span: RecordedSpan{ID: id, Name: name, Attrs: slices.Clone(attrs), Start: time.Now()}}
+
+	if parent, ok := ctx.Value(spanKey{}).(*recording); ok && parent.r == r {
+		rec.span.Parent = parent.span.ID
+	}
+
+	return context.WithValue(ctx, spanKey{}, rec), rec
+}
+
+// Spans returns the ended spans in the order they ended.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.spans)
+}
+
+// Attr returns the value of the attribute named key, and whether the span has it.
+func (s RecordedSpan) Attr(key string) (slog.Value, bool) {
+	for _, attr := range s.Attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return slog.Value{}, false
+}
+
+// End records the span in its Recorder.
+func (rec *recording) End(err error) {
+	rec.span.End = time.Now()
+	rec.span.Err = err
+
+	rec.r.mu.Lock()
+	rec.r.spans = append(rec.r.spans, rec.span)
+	rec.r.mu.Unlock()
+}
+
+// End does nothing.
+func (nop) End(error) {}
+
+// trace starts a span through the tracer of g, or of its nearest parent that has one.
+func (g *Graceful) trace(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	for m := g; m != nil; m = m.parent {
+		if m.tracer != nil {
+			return m.tracer.Start(ctx, name, attrs...)
+		}
+	}
+
+	return ctx, nop{}
+}
+
+// traceService starts a span for an operation on svc.
+func (g *Graceful) traceService(ctx context.Context, name string, svc *ServiceDef) (context.Context, Span) {
+	return g.trace(ctx, name, slog.String("service", g.qualify(svc.Name)))
+}
@@ -0,0 +1,92 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// span returns the recorded span with the given name and service attribute.
+func span(t *testing.T, spans []graceful.RecordedSpan, name, service string) graceful.RecordedSpan {
+	t.Helper()
+
+	for _, s := range spans {
+		got := ""
+		if v, ok := s.Attr("service"); ok {
+			got = v.String()
+		}
+
+		if s.Name == name && got == service {
+			return s
+		}
+	}
+
+	t.Fatalf("no span %s for %q", name, service)
+
+	return graceful.RecordedSpan{}
+}
+
+// root returns the recorded root span with the given name.
+func root(t *testing.T, spans []graceful.RecordedSpan, name string) graceful.RecordedSpan {
+	t.Helper()
+
+	for _, s := range spans {
+		if s.Name == name && s.Parent == 0 {
+			return s
+		}
+	}
+
+	t.Fatalf("no root span %s", name)
+
+	return graceful.RecordedSpan{}
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("Records phases and services as parent and child spans", func(t *testing.T) {
+		recorder := graceful.NewRecorder()
+
+		child := graceful.New()
+		child.Add("db", &MockSvc{name: "db"})
+
+		g := graceful.New(graceful.WithTracer(recorder))
+		g.Add("billing", child)
+		g.Add("cache", graceful.FromFuncs(nil, func(ctx context.Context) error { return errors.New("flush failed") }))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.Error(t, g.Stop(ctx))
+
+		spans := recorder.Spans()
+
+		start := root(t, spans, "graceful.start")
+		assert.NoError(t, start.Err)
+
+		// The child manager starts within the span of its service in the parent.
+		billing := span(t, spans, "graceful.service.start", "billing")
+		assert.Equal(t, start.ID, billing.Parent)
+
+		var nested graceful.RecordedSpan
+		for _, s := range spans {
+			if s.Name == "graceful.start" && s.Parent == billing.ID {
+				nested = s
+			}
+		}
+
+		assert.Equal(t, billing.ID, nested.Parent)
+
+		db := span(t, spans, "graceful.service.start", "billing/db")
+		assert.Equal(t, nested.ID, db.Parent)
+		assert.False(t, db.End.Before(db.Start))
+
+		stop := root(t, spans, "graceful.stop")
+		cache := span(t, spans, "graceful.service.stop", "cache")
+		assert.Equal(t, stop.ID, cache.Parent)
+		assert.ErrorContains(t, cache.Err, "flush failed")
+		assert.ErrorContains(t, stop.Err, "flush failed")
+	})
+}
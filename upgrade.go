@@ -0,0 +1,186 @@
+//go:build unix
+
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// Upgrader replaces the running process with a new copy of its executable without closing its listening sockets.
+	//
+	// The new process inherits the listeners of a Listeners registry and signals readiness once its manager started.
+	// Only then does the current process stop; if the new process fails to become ready, it is killed and the current
+	// process keeps serving.
+	Upgrader struct {
+		listeners *Listeners    // Registry whose listeners are handed off.
+		timeout   time.Duration // Time the new process has to become ready.
+		mu        sync.Mutex    // Guards the fields below.
+		ready     *os.File      // Pipe to the parent process, if started by an upgrade.
+		upgrading bool          // Whether an upgrade is in progress or done.
+	}
+)
+
+// envReadyFd is the descriptor an upgraded process signals readiness on.
+const envReadyFd = "GRACEFUL_READY_FD"
+
+// ErrUpgradeInProgress is returned by Upgrade if another upgrade is in progress or has completed.
+var ErrUpgradeInProgress = errors.New("graceful: upgrade in progress")
+
+// NewUpgrader creates an Upgrader handing off the listeners of l, giving the new process timeout to become ready.
+func NewUpgrader(l *Listeners, timeout time.Duration) *Upgrader {
+	u := &Upgrader{listeners: l, timeout: timeout}
+
+	if v, ok := os.LookupEnv(envReadyFd); ok {
+		if fd, err := strconv.Atoi(v); err == nil {
+			u.ready = os.NewFile(uintptr(fd), "ready")
+		}
+
+		_ = os.Unsetenv(envReadyFd)
+	}
+
+	return u
+}
+
+// WithUpgrader makes Run upgrade the process through u on SIGUSR2, stopping all services once the new process is
+// ready. In a process started by an upgrade, readiness is signalled to the parent process once Start succeeds.
+func WithUpgrader(u *Upgrader) Option {
+	return func(g *Graceful) {
+		g.observers = append(g.observers, func(ev Event) {
+			if ev.Service == "" && ev.State == StateRunning {
+				_ = u.Ready()
+			}
+		})
+
+		g.triggers = append(g.triggers, trigger{
+			signal: syscall.SIGUSR2,
+			handle: func(ctx context.Context) (bool, error) {
+				if err := u.Upgrade(ctx); err != nil {
+					return false, err
+				}
+
+				return true, nil
+			},
+		})
+	}
+}
+
+// Ready signals the parent process that the current process is ready to take over. It is a no-op if the process was
+// not started by an upgrade, or readiness has already been signalled.
+func (u *Upgrader) Ready() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.ready == nil {
+		return nil
+	}
+
+	_, err := u.ready.Write([]byte{1})
+
+	u.ready.Close()
+	u.ready = nil
+
+	return err
+}
+
+// Upgrade starts a new copy of the executable with the same arguments, passing it the listeners in use, and waits for
+// it to become ready. On success the caller is expected to stop its services; on failure the new process is killed.
+func (u *Upgrader) Upgrade(ctx context.Context) error {
+	u.mu.Lock()
+	if u.upgrading {
+		u.mu.Unlock()
+		return ErrUpgradeInProgress
+	}
+
+	u.upgrading = true
+	u.mu.Unlock()
+
+	err := u.upgrade(ctx)
+	if err != nil {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+	}
+
+	return err
+}
+
+func (u *Upgrader) upgrade(ctx context.Context) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	files, names, err := u.listeners.handoff()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(os.Environ(),
+		envListenNames+"="+strings.Join(names, ":"),
+		envReadyFd+"="+strconv.Itoa(listenFdsStart+len(files)),
+	)
+
+	err = cmd.Start()
+
+	w.Close()
+
+	if err != nil {
+		return err
+	}
+
+	ready := make(chan error, 1)
+
+	go func() {
+		// Read returns io.EOF if the process exits without signalling readiness.
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	timer := time.NewTimer(u.timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = fmt.Errorf("not ready after %s", u.timeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return fmt.Errorf("graceful: upgrade to process %d failed: %w", cmd.Process.Pid, err)
+	}
+
+	u.listeners.release()
+
+	return cmd.Process.Release()
+}
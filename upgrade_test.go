@@ -0,0 +1,163 @@
+//go:build unix
+
+package graceful_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+// TestUpgradeHelper runs in a child process started by TestUpgrader, serving its pid on a TCP listener. When started
+// by an upgrade with GRACEFUL_UPGRADE_FAIL set, it fails to start.
+func TestUpgradeHelper(t *testing.T) {
+	if os.Getenv("GRACEFUL_UPGRADE_HELPER") == "" {
+		t.Skip("helper process")
+	}
+
+	listeners, err := graceful.NewListeners()
+	if err != nil {
+		os.Exit(1)
+	}
+
+	upgraded := len(listeners.Inherited()) > 0
+	upgrader := graceful.NewUpgrader(listeners, 5*time.Second)
+
+	srv := graceful.NewTCPServer("tcp", "127.0.0.1:0", func(ctx context.Context, conn net.Conn) {
+		_, _ = fmt.Fprintf(conn, "%d\n", os.Getpid())
+	}, listeners.Use("pid"))
+
+	g := graceful.New(
+		graceful.WithListeners(listeners),
+		graceful.WithUpgrader(upgrader),
+		graceful.WithObserver(func(ev graceful.Event) {
+			if ev.Service == "" && ev.State == graceful.StateRunning {
+				fmt.Printf("ready %d %s\n", os.Getpid(), srv.Addr())
+			}
+		}),
+	)
+	g.Add("pid", srv)
+
+	if upgraded && os.Getenv("GRACEFUL_UPGRADE_FAIL") != "" {
+		g.Add("broken", graceful.FromFuncs(func(ctx context.Context) error { return errors.New("broken") }, nil))
+	}
+
+	_ = g.Run(context.Background(), time.Second)
+
+	os.Exit(0)
+}
+
+// upgradable starts the upgrade helper and returns it with its pid and address once ready.
+func upgradable(t *testing.T, env ...string) (*exec.Cmd, *bufio.Reader, string) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestUpgradeHelper$")
+	cmd.Env = append(append(os.Environ(), "GRACEFUL_UPGRADE_HELPER=1"), env...)
+
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	out := bufio.NewReader(stdout)
+	pid, addr := readyLine(t, out)
+	assert.Equal(t, cmd.Process.Pid, pid)
+
+	return cmd, out, addr
+}
+
+// readyLine reads the next ready line of a helper process.
+func readyLine(t *testing.T, out *bufio.Reader) (int, string) {
+	t.Helper()
+
+	line, err := out.ReadString('\n')
+	require.NoError(t, err)
+
+	var (
+		pid  int
+		addr string
+	)
+
+	_, err = fmt.Sscanf(strings.TrimSpace(line), "ready %d %s", &pid, &addr)
+	require.NoError(t, err)
+
+	return pid, addr
+}
+
+// served returns the pid of the process serving addr.
+func served(t *testing.T, addr string) int {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	var pid int
+
+	_, err = fmt.Fscanf(conn, "%d\n", &pid)
+	require.NoError(t, err)
+
+	return pid
+}
+
+func TestUpgrader(t *testing.T) {
+	t.Run("Hands off listeners to the new process", func(t *testing.T) {
+		parent, out, addr := upgradable(t)
+		assert.Equal(t, parent.Process.Pid, served(t, addr))
+
+		require.NoError(t, parent.Process.Signal(syscall.SIGUSR2))
+
+		pid, upgraded := readyLine(t, out)
+		assert.NotEqual(t, parent.Process.Pid, pid)
+		assert.Equal(t, addr, upgraded)
+
+		// The parent drains and exits, the new process keeps serving on the same socket.
+		require.NoError(t, parent.Wait())
+		assert.Equal(t, pid, served(t, addr))
+
+		child, err := os.FindProcess(pid)
+		require.NoError(t, err)
+		require.NoError(t, child.Signal(syscall.SIGTERM))
+	})
+
+	t.Run("Keeps serving if the new process fails", func(t *testing.T) {
+		parent, out, addr := upgradable(t, "GRACEFUL_UPGRADE_FAIL=1")
+
+		require.NoError(t, parent.Process.Signal(syscall.SIGUSR2))
+
+		// The failed process exits without becoming ready.
+		lines := make(chan string, 1)
+
+		go func() {
+			line, _ := out.ReadString('\n')
+			lines <- line
+		}()
+
+		time.Sleep(500 * time.Millisecond)
+		assert.Equal(t, parent.Process.Pid, served(t, addr))
+
+		select {
+		case line := <-lines:
+			t.Fatalf("unexpected output %q", line)
+		default:
+		}
+
+		require.NoError(t, parent.Process.Signal(syscall.SIGTERM))
+		require.NoError(t, parent.Wait())
+	})
+}
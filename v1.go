@@ -101,7 +101,10 @@ package graceful
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 )
@@ -115,8 +118,41 @@ type (
 
 	// Interruptable represents a function that can be gracefully interrupted.
 	Interruptable func(interrupt <-chan any) error
+
+	// ShutdownOption configures Shutdown.
+	ShutdownOption func(*shutdownConfig)
+
+	// shutdownConfig holds the options of Shutdown.
+	shutdownConfig struct {
+		logger *slog.Logger // Logs the shutdown, slog.Default if nil.
+		level  slog.Leveler // Level of the shutdown records, slog.LevelInfo if nil.
+		dump   string       // File goroutine stacks are written to on timeout, empty to use the logger.
+	}
 )
 
+// WithShutdownLogger makes Shutdown log to l. A nil logger disables logging.
+//
+// Shutdown logs the same records as a manager: "graceful: manager stopping" when it begins, one record per cleanup,
+// named cleanup/0, cleanup/1 and so on in the service attribute, and "graceful: manager stopped" or
+// "graceful: manager failed" when it ends.
+func WithShutdownLogger(l *slog.Logger) ShutdownOption {
+	if l == nil {
+		l = slog.New(discard{})
+	}
+
+	return func(c *shutdownConfig) {
+		c.logger = l
+	}
+}
+
+// WithShutdownLogLevel sets the level the records of Shutdown are logged at, slog.LevelInfo by default. Failed cleanups
+// are logged at slog.LevelError or above.
+func WithShutdownLogLevel(level slog.Leveler) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.level = level
+	}
+}
+
 // GrabAndGo simplifies the use of Go with functions that require an argument.
 func GrabAndGo[T any](fn Parameterized[T], arg T) func() error {
 	return func() error {
@@ -153,35 +189,66 @@ func Go(ctx context.Context, fn func() error, errs chan error) {
 //
 // This function is intended to be used in conjunction with the Go function to handle errors from goroutines and ensure
 // a graceful shutdown.
-func Shutdown(ctx context.Context, cleanups []Cleanup, interrupt chan any, timeout time.Duration, code int) int {
+func Shutdown(
+	ctx context.Context, cleanups []Cleanup, interrupt chan any, timeout time.Duration, code int, opts ...ShutdownOption,
+) int {
+	cfg := &shutdownConfig{logger: slog.Default(), level: slog.LevelInfo}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	began := time.Now()
+	level := cfg.level.Level()
+
+	logEvent(cfg.logger, level, Event{State: StateStopping, Time: began})
+
 	interrupt <- nil
 
 	var (
-		wg sync.WaitGroup
-		mu sync.Mutex
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		pending = make(map[int]bool, len(cleanups)) // Cleanups still running.
 	)
 
 	wg.Add(len(cleanups))
 
-	for _, cleanup := range cleanups {
+	for i := range cleanups {
+		pending[i] = true
+	}
+
+	for i, cleanup := range cleanups {
 		go func() {
 			defer wg.Done()
 
-			if err := cleanup(ctx); err != nil {
-				mu.Lock()
-				defer mu.Unlock()
+			start := time.Now()
+			err := cleanup(ctx)
+
+			ev := Event{Service: fmt.Sprintf("cleanup/%d", i), State: StateStopped, Err: err, Time: time.Now()}
+			ev.Duration = ev.Time.Sub(start)
+
+			if err != nil {
+				ev.State = StateFailed
+			}
+
+			logEvent(cfg.logger, level, ev)
+
+			mu.Lock()
+			defer mu.Unlock()
 
-				slog.Warn("graceful: cleanup failed", "error", err)
+			delete(pending, i)
 
+			if err != nil {
 				code = 1
+				errs = append(errs, err)
 			}
 		}()
 	}
 
-	done := make(chan bool)
+	done := make(chan struct{})
 	go func() {
 		wg.Wait()
-		done <- true
+		close(done)
 	}()
 
 	select {
@@ -189,14 +256,34 @@ func Shutdown(ctx context.Context, cleanups []Cleanup, interrupt chan any, timeo
 		// All cleanups completed within the timeout.
 	case <-time.After(timeout):
 		mu.Lock()
-		defer mu.Unlock()
+		stuck := make([]string, 0, len(pending))
 
-		slog.Warn("graceful: shutdown timeout reached, some cleanups may not have completed")
+		for i := range cleanups {
+			if pending[i] {
+				stuck = append(stuck, fmt.Sprintf("cleanup/%d", i))
+			}
+		}
 
 		code = 1
+		errs = append(errs, fmt.Errorf("shutdown timeout of %s reached, still running: %s", timeout,
+			strings.Join(stuck, ", ")))
+		mu.Unlock()
+
+		cfg.logger.Warn("graceful: shutdown timeout reached, cleanups still running", "cleanups", stuck)
+		dumpGoroutines(cfg.logger, cfg.dump)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ev := Event{State: StateStopped, Err: errors.Join(errs...), Time: time.Now()}
+	ev.Duration = ev.Time.Sub(began)
+
+	if ev.Err != nil {
+		ev.State = StateFailed
 	}
 
-	close(done)
+	logEvent(cfg.logger, level, ev)
 
 	return code
 }
@@ -0,0 +1,215 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Watcher polls files for changes and reloads or restarts the services bound to them.
+	//
+	// Polling works on any file system and needs no platform notification API. A file changes when its identity, size,
+	// mode or modification time changes, so replacing it by a rename, or the atomic symlink swap Kubernetes performs on
+	// ConfigMap and Secret volumes, is detected as well as writes in place. A watched directory changes when entries are
+	// added, removed or renamed in it.
+	//
+	// Changes are debounced: services are triggered once a file has not changed for the debounce period, so a burst of
+	// writes causes a single reload. A file that is removed triggers its services once it reappears.
+	Watcher struct {
+		interval time.Duration // Time between polls.
+		debounce time.Duration // Time a change has to settle before services are triggered.
+		mu       sync.Mutex    // Guards the fields below.
+		watches  []*watch      // Watched paths, in the order they were added.
+		cancel   context.CancelFunc
+		done     chan struct{} // Closed when polling stopped.
+	}
+
+	// watch binds a path to the services triggered by its changes.
+	watch struct {
+		path     string
+		services []string
+		restart  bool        // Whether services are restarted rather than reloaded.
+		last     os.FileInfo // Last observed state of the file, nil if it did not exist.
+		changed  time.Time   // When a pending change was last observed, zero if there is none.
+	}
+)
+
+// NewWatcher creates a Watcher polling every interval and triggering services once changes settled for debounce. A
+// non-positive interval polls every second, and a negative debounce triggers services as soon as a change is seen.
+func NewWatcher(interval, debounce time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	return &Watcher{interval: interval, debounce: max(debounce, 0)}
+}
+
+// WithWatcher makes the manager reload or restart services when files watched by w change. Polling begins once Start
+// succeeds and ends when Stop begins.
+func WithWatcher(w *Watcher) Option {
+	return func(g *Graceful) {
+		g.observers = append(g.observers, func(ev Event) {
+			if ev.Service != "" {
+				return
+			}
+
+			switch ev.State {
+			case StateRunning:
+				w.start(g)
+			case StateStopping, StateFailed:
+				w.halt()
+			}
+		})
+	}
+}
+
+// Reload makes changes of path reload the named services. Names may be hierarchical, e.g. "billing/db". Services that
+// do not implement Reloadable are ignored.
+func (w *Watcher) Reload(path string, services ...string) {
+	w.add(path, services, false)
+}
+
+// Restart makes changes of path restart the named services, see Graceful.Restart. A service both reloaded and
+// restarted by a change is only restarted.
+func (w *Watcher) Restart(path string, services ...string) {
+	w.add(path, services, true)
+}
+
+// add binds path to services.
+func (w *Watcher) add(path string, services []string, restart bool) {
+	info, _ := os.Stat(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.watches = append(w.watches, &watch{path: path, services: services, restart: restart, last: info})
+}
+
+// start begins polling on behalf of g, unless it is already polling.
+func (w *Watcher) start(g *Graceful) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	w.cancel, w.done = cancel, done
+
+	// Changes made while the manager was not running are picked up by the first poll.
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.poll(ctx, g); err != nil {
+					logger, _ := g.log()
+					logger.Warn("graceful: file change handling failed", "error", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// halt stops polling, waiting for services being reloaded or restarted.
+func (w *Watcher) halt() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// poll checks all watched paths and triggers the services of settled changes.
+func (w *Watcher) poll(ctx context.Context, g *Graceful) error {
+	reload, restart := w.settled(time.Now())
+
+	errs := make([]error, 0)
+
+	for _, name := range restart {
+		if err := g.Restart(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range reload {
+		if slices.Contains(restart, name) {
+			continue
+		}
+
+		m, svc, ok := g.lookup(name)
+		if !ok {
+			errs = append(errs, NewGracefulError(g.qualify(name), "service not found", nil))
+			continue
+		}
+
+		if err := m.reloadService(ctx, svc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// settled records the changes observed at now and returns the services to reload and to restart for changes that have
+// settled.
+func (w *Watcher) settled(now time.Time) ([]string, []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var reload, restart []string
+
+	for _, wt := range w.watches {
+		info, _ := os.Stat(wt.path)
+
+		if !same(wt.last, info) {
+			wt.last = info
+			wt.changed = now
+
+			continue
+		}
+
+		if wt.changed.IsZero() || now.Sub(wt.changed) < w.debounce || info == nil {
+			continue
+		}
+
+		wt.changed = time.Time{}
+
+		for _, name := range wt.services {
+			switch {
+			case wt.restart && !slices.Contains(restart, name):
+				restart = append(restart, name)
+			case !wt.restart && !slices.Contains(reload, name):
+				reload = append(reload, name)
+			}
+		}
+	}
+
+	return reload, restart
+}
+
+// same reports whether two observations of a file are identical, nil standing for a missing file.
+func same(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.Mode() == b.Mode() && a.ModTime().Equal(b.ModTime())
+}
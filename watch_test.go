@@ -0,0 +1,120 @@
+package graceful_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/graceful"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Run("Reloads on writes and debounces bursts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+		order := make([]string, 0)
+		svc := &ConfigSvc{MockSvc: MockSvc{name: "api"}, order: &order, mu: &sync.Mutex{}}
+
+		w := graceful.NewWatcher(10*time.Millisecond, 100*time.Millisecond)
+		w.Reload(path, "api")
+
+		g := graceful.New(graceful.WithWatcher(w))
+		g.Add("api", svc)
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		for _, v := range []string{"v2", "v3 longer", "v4"} {
+			require.NoError(t, os.WriteFile(path, []byte(v), 0o600))
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		assert.Eventually(t, func() bool {
+			svc.mu.Lock()
+			defer svc.mu.Unlock()
+
+			return svc.version == 1
+		}, time.Second, 10*time.Millisecond)
+
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, g.Stop(ctx))
+
+		assert.Equal(t, 1, svc.version)
+	})
+
+	t.Run("Restarts on symlink swaps", func(t *testing.T) {
+		// Kubernetes mounts ConfigMaps as a symlink to a ..data symlink, which is atomically swapped on updates.
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "v1"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "v1", "config.yaml"), []byte("v1"), 0o600))
+		require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+		var starts, stops atomic.Int32
+
+		w := graceful.NewWatcher(10*time.Millisecond, 30*time.Millisecond)
+		w.Restart(filepath.Join(dir, "config.yaml"), "worker")
+
+		g := graceful.New(graceful.WithWatcher(w))
+		g.Add("worker", counted(&starts, &stops))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "v2"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "v2", "config.yaml"), []byte("v2"), 0o600))
+		require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+		assert.Eventually(t, func() bool { return starts.Load() == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(1), stops.Load())
+
+		require.NoError(t, g.Stop(ctx))
+	})
+
+	t.Run("Stops polling with the manager", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+		var starts, stops atomic.Int32
+
+		w := graceful.NewWatcher(10*time.Millisecond, 10*time.Millisecond)
+		w.Restart(path, "worker")
+
+		g := graceful.New(graceful.WithWatcher(w))
+		g.Add("worker", counted(&starts, &stops))
+
+		ctx := context.Background()
+		require.NoError(t, g.Start(ctx))
+		require.NoError(t, g.Stop(ctx))
+
+		require.NoError(t, os.WriteFile(path, []byte("v2"), 0o600))
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, int32(1), starts.Load())
+	})
+	t.Run("Defaults a non-positive interval", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+		var starts, stops atomic.Int32
+
+		w := graceful.NewWatcher(0, -time.Second)
+		w.Restart(path, "worker")
+
+		g := graceful.New(graceful.WithWatcher(w))
+		g.Add("worker", counted(&starts, &stops))
+
+		ctx := context.Background()
+		require.NotPanics(t, func() { require.NoError(t, g.Start(ctx)) })
+		require.NoError(t, g.Stop(ctx))
+	})
+}